@@ -0,0 +1,63 @@
+package xtid
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+var errASN1 = errors.New("xtid: invalid ASN.1 XTID")
+
+// MarshalASN1 returns the DER encoding of the ID as an OCTET STRING of its
+// 20 bytes, the form carried in certificate extensions and CMS attributes.
+func (i XTID) MarshalASN1() ([]byte, error) {
+	return asn1.Marshal(i[:])
+}
+
+// UnmarshalASN1 decodes the DER OCTET STRING written by MarshalASN1. It
+// rejects trailing data.
+func (i *XTID) UnmarshalASN1(der []byte) error {
+	var b []byte
+	rest, err := asn1.Unmarshal(der, &b)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errASN1, err)
+	}
+	if len(rest) != 0 || len(b) != byteLength {
+		return errASN1
+	}
+	id, err := FromBytes(b)
+	if err != nil {
+		return err
+	}
+	*i = id
+	return nil
+}
+
+// Extension returns an X.509 extension identified by oid whose value is the
+// ID, encoded by MarshalASN1, to be added to the ExtraExtensions of a
+// certificate or the attributes of a request.
+func (i XTID) Extension(oid asn1.ObjectIdentifier, critical bool) (pkix.Extension, error) {
+	der, err := i.MarshalASN1()
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: oid, Critical: critical, Value: der}, nil
+}
+
+// FromExtensions returns the ID carried by the extension identified by oid
+// in exts, as found in the Extensions of a parsed certificate. It reports
+// false when there is no such extension.
+func FromExtensions(exts []pkix.Extension, oid asn1.ObjectIdentifier) (XTID, bool, error) {
+	for _, ext := range exts {
+		if !ext.Id.Equal(oid) {
+			continue
+		}
+		var id XTID
+		if err := id.UnmarshalASN1(ext.Value); err != nil {
+			return Nil, true, err
+		}
+		return id, true, nil
+	}
+	return Nil, false, nil
+}
@@ -3,31 +3,34 @@ package xtid
 import (
 	"encoding/binary"
 	"errors"
+	"math/bits"
 )
 
 const (
 	// lexographic ordering (based on Unicode table) is 0-9A-Za-z
 	base62Characters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 	zeroString       = "000000000000000000000000000"
-	offsetUppercase  = 10
-	offsetLowercase  = 36
+
+	// 62^10, the largest power of 62 below 2^64
+	base62Pow10 = 839299365868340224
 )
 
 var (
 	errShortBuffer = errors.New("the output buffer is too small to hold to decoded value")
 )
 
-// Converts a base 62 byte into the number value that it represents.
-func base62Value(digit byte) byte {
-	switch {
-	case digit >= '0' && digit <= '9':
-		return digit - '0'
-	case digit >= 'A' && digit <= 'Z':
-		return offsetUppercase + (digit - 'A')
-	default:
-		return offsetLowercase + (digit - 'a')
+// The value of each base62 digit, or invalidDigit for bytes which are not
+const invalidDigit = 0xff
+
+var base62Digits = func() (t [256]byte) {
+	for j := range t {
+		t[j] = invalidDigit
 	}
-}
+	for j := 0; j < len(base62Characters); j++ {
+		t[base62Characters[j]] = byte(j)
+	}
+	return
+}()
 
 // This function encodes the base 62 representation of the src XAID in binary
 // form into dst.
@@ -35,50 +38,38 @@ func base62Value(digit byte) byte {
 // In order to support a couple of optimizations the function assumes that src
 // is 20 bytes long and dst is 27 bytes long.
 //
-// Any unused bytes in dst will be set to the padding '0' byte.
+// The 160-bit value is held in 64-bit limbs and divided twice by 62^10, the
+// largest power of 62 which fits in a uint64, which leaves 3 groups of
+// digits converted using native division by the constant 62.
 func fastEncodeBase62(dst []byte, src []byte) {
-	const srcBase = 4294967296
-	const dstBase = 62
-
-	// Split src into 5 4-byte words, this is where most of the efficiency comes
-	// from because this is a O(N^2) algorithm, and we make N = N / 4 by working
-	// on 32 bits at a time.
-	parts := [5]uint32{
-		binary.BigEndian.Uint32(src[0:4]),
-		binary.BigEndian.Uint32(src[4:8]),
-		binary.BigEndian.Uint32(src[8:12]),
-		binary.BigEndian.Uint32(src[12:16]),
-		binary.BigEndian.Uint32(src[16:20]),
-	}
-
-	n := len(dst)
-	bp := parts[:]
-	bq := [5]uint32{}
-
-	for len(bp) != 0 {
-		quotient := bq[:0]
-		remainder := uint64(0)
-
-		for _, c := range bp {
-			value := uint64(c) + uint64(remainder)*srcBase
-			digit := value / dstBase
-			remainder = value % dstBase
-
-			if len(quotient) != 0 || digit != 0 {
-				quotient = append(quotient, uint32(digit))
-			}
-		}
+	// These lines help BCE (Bounds Check Elimination).
+	_ = dst[26]
+	_ = src[19]
+
+	hi := uint64(binary.BigEndian.Uint32(src[0:4]))
+	mid := binary.BigEndian.Uint64(src[4:12])
+	lo := binary.BigEndian.Uint64(src[12:20])
+
+	// hi is below 2^32, so the quotients can't overflow
+	var r uint64
+	mid, r = bits.Div64(hi, mid, base62Pow10)
+	lo, r = bits.Div64(r, lo, base62Pow10)
+	encodeBase62Digits(dst[17:27], r)
+
+	mid, r = bits.Div64(0, mid, base62Pow10)
+	lo, r = bits.Div64(r, lo, base62Pow10)
+	encodeBase62Digits(dst[7:17], r)
+
+	// What remains is below 2^160 / 62^20, that is 62^7
+	encodeBase62Digits(dst[0:7], lo)
+}
 
-		// Writes at the end of the destination buffer because we computed the
-		// lowest bits first.
-		n--
-		dst[n] = base62Characters[remainder]
-		bp = quotient
+// Writes the len(dst) lowest base 62 digits of v into dst.
+func encodeBase62Digits(dst []byte, v uint64) {
+	for j := len(dst) - 1; j >= 0; j-- {
+		dst[j] = base62Characters[v%62]
+		v /= 62
 	}
-
-	// Add padding at the head of the destination buffer for all bytes that were
-	// not set.
-	copy(dst[:n], zeroString)
 }
 
 // This function appends the base 62 representation of the XAID in src to dst,
@@ -96,82 +87,70 @@ func fastAppendEncodeBase62(dst []byte, src []byte) []byte {
 // binary form into dst.
 //
 // In order to support a couple of optimizations the function assumes that src
-// is 27 bytes long and dst is 20 bytes long.
+// is 27 bytes long and dst is 20 bytes long. src can be a string, so that it
+// is decoded without being copied first.
 //
-// Any unused bytes in dst will be set to zero.
-func fastDecodeBase62(dst []byte, src []byte) error {
-	const srcBase = 62
-	const dstBase = 4294967296
-
+// Invalid digits and values out of range are reported as errors.
+func fastDecodeBase62[S ~string | ~[]byte](dst []byte, src S) error {
 	// This line helps BCE (Bounds Check Elimination).
-	// It may be safely removed.
-	_ = src[26]
+	_ = dst[19]
 
-	parts := [27]byte{
-		base62Value(src[0]),
-		base62Value(src[1]),
-		base62Value(src[2]),
-		base62Value(src[3]),
-		base62Value(src[4]),
-		base62Value(src[5]),
-		base62Value(src[6]),
-		base62Value(src[7]),
-		base62Value(src[8]),
-		base62Value(src[9]),
-
-		base62Value(src[10]),
-		base62Value(src[11]),
-		base62Value(src[12]),
-		base62Value(src[13]),
-		base62Value(src[14]),
-		base62Value(src[15]),
-		base62Value(src[16]),
-		base62Value(src[17]),
-		base62Value(src[18]),
-		base62Value(src[19]),
-
-		base62Value(src[20]),
-		base62Value(src[21]),
-		base62Value(src[22]),
-		base62Value(src[23]),
-		base62Value(src[24]),
-		base62Value(src[25]),
-		base62Value(src[26]),
+	invalid, hi, mid, lo := decodeBase62Limbs(src)
+	if invalid != 0 {
+		return errStrChar
+	}
+	// Values over 2^160 are out of range
+	if hi > 0xffffffff {
+		return errStrValue
 	}
 
-	n := len(dst)
-	bp := parts[:]
-	bq := [stringEncodedLength]byte{}
-
-	for len(bp) > 0 {
-		quotient := bq[:0]
-		remainder := uint64(0)
-
-		for _, c := range bp {
-			value := uint64(c) + uint64(remainder)*srcBase
-			digit := value / dstBase
-			remainder = value % dstBase
+	binary.BigEndian.PutUint32(dst[0:4], uint32(hi))
+	binary.BigEndian.PutUint64(dst[4:12], mid)
+	binary.BigEndian.PutUint64(dst[12:20], lo)
+	return nil
+}
 
-			if len(quotient) != 0 || digit != 0 {
-				quotient = append(quotient, byte(digit))
-			}
-		}
+// Returns the value of the 27 base 62 digits of src over 64-bit limbs,
+// without branching on them: invalid is non-zero when src holds invalid
+// digits, and hi exceeds 32 bits when the value is out of range.
+//
+// The digits are accumulated in native uint64 arithmetic in groups of 7, 10
+// and 10, which are then combined by multiplying by 62^10.
+func decodeBase62Limbs[S ~string | ~[]byte](src S) (invalid byte, hi, mid, lo uint64) {
+	// This line helps BCE (Bounds Check Elimination).
+	_ = src[26]
 
-		if n < 4 {
-			return errShortBuffer
-		}
+	c0 := decodeBase62Group(src[0:7], &invalid)
+	c1 := decodeBase62Group(src[7:17], &invalid)
+	c2 := decodeBase62Group(src[17:27], &invalid)
+
+	// (c0 * 62^10 + c1) * 62^10 + c2, where c0 * 62^10 is below 2^102
+	var carry uint64
+	th, tl := bits.Mul64(c0, base62Pow10)
+	tl, carry = bits.Add64(tl, c1, 0)
+	th += carry
+
+	h1, lo := bits.Mul64(tl, base62Pow10)
+	h2, l2 := bits.Mul64(th, base62Pow10)
+	lo, carry = bits.Add64(lo, c2, 0)
+	mid, carry = bits.Add64(h1, l2, carry)
+	hi = h2 + carry
+	return
+}
 
-		dst[n-4] = byte(remainder >> 24)
-		dst[n-3] = byte(remainder >> 16)
-		dst[n-2] = byte(remainder >> 8)
-		dst[n-1] = byte(remainder)
-		n -= 4
-		bp = quotient
+// Returns the value of the base 62 digits of src, at most 10 of them, and
+// flags invalid digits in invalid.
+func decodeBase62Group[S ~string | ~[]byte](src S, invalid *byte) uint64 {
+	var v uint64
+	var bad byte
+	for j := 0; j < len(src); j++ {
+		d := base62Digits[src[j]]
+		// Invalid digits have all their bits set, which sticks
+		bad |= d & 0x80
+		v = v*62 + uint64(d)
 	}
-
-	var zero [20]byte
-	copy(dst[:n], zero[:])
-	return nil
+	*invalid |= bad
+	return v
 }
 
 // This function appends the base 62 decoded version of src into dst.
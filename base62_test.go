@@ -0,0 +1,175 @@
+package xtid
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// referenceDecodeBase62 is the quadratic decoder replaced by the limb
+// arithmetic of fastDecodeBase62, which repeatedly divides the digits by
+// 2^32. It reports false for invalid digits and values out of range.
+func referenceDecodeBase62(src string) (dst [byteLength]byte, ok bool) {
+	const srcBase = 62
+	const dstBase = 4294967296
+
+	var parts [stringEncodedLength]byte
+	for j := range parts {
+		d := strings.IndexByte(base62Characters, src[j])
+		if d < 0 {
+			return dst, false
+		}
+		parts[j] = byte(d)
+	}
+
+	n := len(dst)
+	bp := parts[:]
+	bq := [stringEncodedLength]byte{}
+	for len(bp) > 0 {
+		quotient := bq[:0]
+		remainder := uint64(0)
+		for _, c := range bp {
+			value := uint64(c) + remainder*srcBase
+			digit := value / dstBase
+			remainder = value % dstBase
+			if len(quotient) != 0 || digit != 0 {
+				quotient = append(quotient, byte(digit))
+			}
+		}
+		if n < 4 {
+			return dst, false
+		}
+		dst[n-4] = byte(remainder >> 24)
+		dst[n-3] = byte(remainder >> 16)
+		dst[n-2] = byte(remainder >> 8)
+		dst[n-1] = byte(remainder)
+		n -= 4
+		bp = quotient
+	}
+	return dst, true
+}
+
+// referenceEncodeBase62 encodes src with math/big.
+func referenceEncodeBase62(src []byte) string {
+	v := new(big.Int).SetBytes(src)
+	base := big.NewInt(62)
+	var digits [stringEncodedLength]byte
+	for j := len(digits) - 1; j >= 0; j-- {
+		var d big.Int
+		v.DivMod(v, base, &d)
+		digits[j] = base62Characters[d.Int64()]
+	}
+	return string(digits[:])
+}
+
+func FuzzBase62Encode(f *testing.F) {
+	f.Add(Nil[:])
+	f.Add(Max[:])
+	f.Add([]byte("0123456789abcdefghij"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		if len(b) < byteLength {
+			return
+		}
+		src := b[:byteLength]
+
+		var dst [stringEncodedLength]byte
+		fastEncodeBase62(dst[:], src)
+		if want := referenceEncodeBase62(src); string(dst[:]) != want {
+			t.Fatalf("encode(%x) = %s, want %s", src, dst, want)
+		}
+
+		var back [byteLength]byte
+		if err := fastDecodeBase62(back[:], dst[:]); err != nil {
+			t.Fatalf("decode(%s): %v", dst, err)
+		}
+		if !bytes.Equal(back[:], src) {
+			t.Fatalf("decode(encode(%x)) = %x", src, back)
+		}
+	})
+}
+
+func FuzzBase62Decode(f *testing.F) {
+	f.Add(minStringEncoded)
+	f.Add(maxStringEncoded)
+	f.Add("aWgEPTl1tmebfsQzFP4bxwgy80W")
+	f.Add("zzzzzzzzzzzzzzzzzzzzzzzzzzz")
+	f.Add("00DdjxnD9QhSu715j5vwl3AE8Y!")
+	f.Fuzz(func(t *testing.T, s string) {
+		if len(s) != stringEncodedLength {
+			return
+		}
+		want, ok := referenceDecodeBase62(s)
+		var got [byteLength]byte
+		err := fastDecodeBase62(got[:], s)
+		switch {
+		case ok && err != nil:
+			t.Fatalf("decode(%q): %v, want %x", s, err, want)
+		case !ok && err == nil:
+			t.Fatalf("decode(%q) = %x, want an error", s, got)
+		case ok && got != want:
+			t.Fatalf("decode(%q) = %x, want %x", s, got, want)
+		}
+	})
+}
+
+var (
+	benchID  = MustParse("00DdjxnD9QhSu715j5vwl3AE8YK")
+	benchStr = benchID.String()
+	sinkID   XTID
+	sinkStr  string
+)
+
+func BenchmarkParse(b *testing.B) {
+	for range b.N {
+		sinkID, _ = Parse(benchStr)
+	}
+}
+
+// BenchmarkParseReference measures the replaced decoder, for comparison
+// with BenchmarkParse.
+func BenchmarkParseReference(b *testing.B) {
+	for range b.N {
+		sinkID, _ = referenceDecodeBase62(benchStr)
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	for range b.N {
+		sinkStr = benchID.String()
+	}
+}
+
+// BenchmarkStringReference measures math/big encoding, for comparison with
+// BenchmarkString.
+func BenchmarkStringReference(b *testing.B) {
+	for range b.N {
+		sinkStr = referenceEncodeBase62(benchID[:])
+	}
+}
+
+func BenchmarkParseBatch(b *testing.B) {
+	src := make([][]byte, 1024)
+	for j := range src {
+		src[j] = []byte(Must(New()).String())
+	}
+	dst := make([]XTID, len(src))
+	b.ResetTimer()
+	for range b.N {
+		ParseBatch(dst, src)
+	}
+}
+
+func BenchmarkParseLoop(b *testing.B) {
+	src := make([][]byte, 1024)
+	for j := range src {
+		src[j] = []byte(Must(New()).String())
+	}
+	dst := make([]XTID, len(src))
+	b.ResetTimer()
+	for range b.N {
+		for j, s := range src {
+			dst[j], _ = Parse(string(s))
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package xtid
+
+import (
+	"encoding/base64"
+	"errors"
+)
+
+var errBase64 = errors.New("xtid: invalid base64url encoded XTID")
+
+// EncodeBase64 returns the unpadded base64url encoding of the 20 bytes of the
+// ID, 27 characters long like String, for JWT claims and signed URLs where
+// base64url is customary. Unlike the base62 encoding, it doesn't sort like
+// the IDs.
+func (i XTID) EncodeBase64() string {
+	return base64.RawURLEncoding.EncodeToString(i[:])
+}
+
+// ParseBase64 decodes an ID encoded with EncodeBase64.
+func ParseBase64(s string) (XTID, error) {
+	if len(s) != base64.RawURLEncoding.EncodedLen(byteLength) {
+		return Nil, errBase64
+	}
+	var id XTID
+	if n, err := base64.RawURLEncoding.Strict().Decode(id[:], []byte(s)); err != nil || n != byteLength {
+		return Nil, errBase64
+	}
+	return id, nil
+}
@@ -0,0 +1,151 @@
+package xtid
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+// A Builder composes the options of XTID generation in a single chain:
+//
+//	id, err := xtid.Build().Type(7).Nanoseconds().Monotonic().New()
+//
+// A Builder is frozen on the first call to New or Generator: it can then be
+// reused, also concurrently, to mint more IDs with the same options, and
+// further calls to its option methods have no effect on it. The option
+// methods are not safe for concurrent use.
+type Builder struct {
+	opts    []Option
+	t       time.Time
+	typ     uint16
+	payload *[payloadLengthInBytes]byte
+
+	once   sync.Once
+	frozen struct {
+		t       time.Time
+		typ     uint16
+		payload *[payloadLengthInBytes]byte
+	}
+	gen *Generator
+	err error
+}
+
+// Build starts a Builder minting IDs of the default type, for the current
+// time, see New.
+func Build() *Builder {
+	return &Builder{typ: defaultType}
+}
+
+// Time sets the time of the IDs, the current time when unset or zero.
+func (b *Builder) Time(t time.Time) *Builder {
+	b.t = t
+	return b
+}
+
+// Type sets the type of the IDs.
+func (b *Builder) Type(typ uint16) *Builder {
+	b.typ = typ
+	return b
+}
+
+// Payload sets the payload of the IDs in place of random bytes, see
+// MakeWithPayload. The generator then only sets their timestamps: New fails
+// if its options also shape the payload, as WithNodeID, WithTenant,
+// WithRegion, WithMonotonic and the collision guard do. Like those of
+// MakeWithPayload, the IDs are not reported to the OnGenerate hooks.
+func (b *Builder) Payload(p [payloadLengthInBytes]byte) *Builder {
+	b.payload = &p
+	return b
+}
+
+// NodeID reserves the first bytes of the payload for a node ID, see
+// WithNodeID.
+func (b *Builder) NodeID(node uint64, size int) *Builder {
+	return b.With(WithNodeID(node, size))
+}
+
+// Nanoseconds makes the IDs use nanosecond precision, see WithNanoseconds.
+func (b *Builder) Nanoseconds() *Builder {
+	return b.With(WithNanoseconds())
+}
+
+// Monotonic makes the IDs strictly increasing, see WithMonotonic.
+func (b *Builder) Monotonic() *Builder {
+	return b.With(WithMonotonic())
+}
+
+// Epoch makes the IDs count from e, see WithEpoch.
+func (b *Builder) Epoch(e time.Time) *Builder {
+	return b.With(WithEpoch(e))
+}
+
+// With adds generator options.
+func (b *Builder) With(opts ...Option) *Builder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Generator returns the generator set up with the options of the builder,
+// freezing it.
+func (b *Builder) Generator() (*Generator, error) {
+	b.once.Do(func() {
+		b.frozen.t, b.frozen.typ = b.t, b.typ
+		if b.payload != nil {
+			p := *b.payload
+			b.frozen.payload = &p
+		}
+		b.gen, b.err = NewGenerator(b.opts...)
+	})
+	return b.gen, b.err
+}
+
+// New mints an ID.
+func (b *Builder) New() (XTID, error) {
+	g, err := b.Generator()
+	if err != nil {
+		return Nil, err
+	}
+	t := b.frozen.t
+	if t.IsZero() {
+		t = time.Now()
+	}
+	if b.frozen.payload != nil {
+		return g.makeWithPayload(t, b.frozen.typ, b.frozen.payload)
+	}
+	return g.Make(t, b.frozen.typ)
+}
+
+// MustNew is like New, but panics on failure.
+func (b *Builder) MustNew() XTID {
+	return Must(b.New())
+}
+
+var errBuilderPayload = errors.New("xtid: builder payload conflicts with options shaping the payload")
+
+// makeWithPayload mints the ID of time t, type typ and payload p, failing if
+// the options of g shape the payload themselves. The clock policy of g
+// applies as to the IDs it mints.
+func (g *Generator) makeWithPayload(t time.Time, typ uint16, p *[payloadLengthInBytes]byte) (id XTID, err error) {
+	if len(g.prefix) != 0 || len(g.suffix) != 0 || g.monotonic || g.guard != nil {
+		return Nil, errBuilderPayload
+	}
+	ts, err := g.timestamp(t)
+	if err != nil {
+		return Nil, err
+	}
+	if g.clock != ClockIgnore {
+		g.mu.Lock()
+		if ts, _, err = g.checkClock(ts); err == nil {
+			g.lastTs = ts
+		}
+		g.mu.Unlock()
+		if err != nil {
+			return Nil, err
+		}
+	}
+	binary.BigEndian.PutUint64(id[:timestampLengthInBytes], ts)
+	binary.BigEndian.PutUint16(id[timestampLengthInBytes:payloadStart], typ)
+	copy(id[payloadStart:], p[:])
+	return id, nil
+}
@@ -0,0 +1,86 @@
+package xtid
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBuilderFrozen(t *testing.T) {
+	t0 := Epoch().Add(time.Hour)
+	b := Build().Type(7).Time(t0)
+	if _, err := b.New(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Setters racing with New have no effect
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		b.Type(8).Time(t0.Add(time.Hour)).Payload([10]byte{1})
+	}()
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			id, err := b.New()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if id.Type() != 7 || !id.Time().Equal(t0) {
+				t.Errorf("ID of type %d at %v, want 7 at %v", id.Type(), id.Time(), t0)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestBuilderPayload(t *testing.T) {
+	t0 := time.Now()
+	p := [10]byte{1, 2, 3}
+	id, err := Build().Time(t0).Payload(p).New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Payload() != p {
+		t.Errorf("payload %x, want %x", id.Payload(), p)
+	}
+	if _, err := Build().Payload(p).Monotonic().New(); !errors.Is(err, errBuilderPayload) {
+		t.Errorf("New = %v, want %v", err, errBuilderPayload)
+	}
+
+	// The clock policy applies to IDs with a payload too
+	b := Build().Payload(p).With(WithClockPolicy(ClockError))
+	g, err := b.Generator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.makeWithPayload(t0, 1, &p); err != nil {
+		t.Fatal(err)
+	}
+	var cre *ClockRegressionError
+	if _, err := g.makeWithPayload(t0.Add(-time.Second), 1, &p); !errors.As(err, &cre) {
+		t.Errorf("makeWithPayload = %v, want a ClockRegressionError", err)
+	}
+	if _, err := g.Make(t0.Add(-time.Second), 1); !errors.As(err, &cre) {
+		t.Errorf("Make = %v, want a ClockRegressionError", err)
+	}
+
+	g, err = NewGenerator(WithClockPolicy(ClockHold))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.makeWithPayload(t0, 1, &p); err != nil {
+		t.Fatal(err)
+	}
+	id, err = g.makeWithPayload(t0.Add(-time.Second), 1, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := t0.Truncate(time.Microsecond); !id.Time().Equal(want) {
+		t.Errorf("held time %v, want %v", id.Time(), want)
+	}
+}
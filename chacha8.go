@@ -0,0 +1,64 @@
+package xtid
+
+import (
+	crand "crypto/rand"
+	"io"
+	"math/rand/v2"
+	"sync"
+)
+
+// Number of bytes a ChaCha8 source generates before reseeding
+const chacha8ReseedInterval = 1 << 20
+
+type chacha8State struct {
+	rng  *rand.ChaCha8
+	left int
+}
+
+type chacha8Source struct {
+	pool sync.Pool
+}
+
+// ChaCha8Source returns a source generating random bytes in userspace with
+// a ChaCha8 stream seeded from crypto/rand. Every stream is reseeded after
+// producing 1 MiB. It is much cheaper than reading from the operating
+// system for every ID:
+//
+//	xtid.SetSource(xtid.ChaCha8Source())
+func ChaCha8Source() io.Reader {
+	return &chacha8Source{
+		pool: sync.Pool{
+			New: func() any {
+				return &chacha8State{}
+			},
+		},
+	}
+}
+
+func (s *chacha8Source) Read(p []byte) (n int, err error) {
+	st := s.pool.Get().(*chacha8State)
+	defer s.pool.Put(st)
+
+	for n < len(p) {
+		if st.left == 0 {
+			var seed [32]byte
+			if _, err = io.ReadFull(crand.Reader, seed[:]); err != nil {
+				return
+			}
+			st.rng = rand.NewChaCha8(seed)
+			st.left = chacha8ReseedInterval
+		}
+
+		c := min(len(p)-n, st.left)
+		st.rng.Read(p[n : n+c])
+		st.left -= c
+		n += c
+	}
+
+	return
+}
+
+func (s *chacha8Source) FillPayload(p *[payloadLengthInBytes]byte) error {
+	_, err := s.Read(p[:])
+	return err
+}
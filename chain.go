@@ -0,0 +1,76 @@
+package xtid
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// How long a ChainedSource keeps using the fallback before trying its
+// primary again
+const chainRetryInterval = time.Minute
+
+// SourceHealth describes the state of a ChainedSource.
+type SourceHealth struct {
+	// Whether the primary source is in use
+	Healthy bool
+	// Number of failed reads from the primary source
+	Failures uint64
+	// The last error returned by the primary source
+	LastError error
+	// When the primary source last failed
+	FailedAt time.Time
+}
+
+// ChainedSource reads from a primary source, failing over to a fallback
+// when the primary returns an error. The primary is retried periodically and
+// used again as soon as it recovers.
+type ChainedSource struct {
+	primary  io.Reader
+	fallback io.Reader
+
+	mu     sync.Mutex
+	health SourceHealth
+}
+
+// ChainSource returns a source reading from primary, or from fallback while
+// primary is failing.
+func ChainSource(primary, fallback io.Reader) *ChainedSource {
+	return &ChainedSource{
+		primary:  primary,
+		fallback: fallback,
+		health:   SourceHealth{Healthy: true},
+	}
+}
+
+func (c *ChainedSource) Read(p []byte) (int, error) {
+	c.mu.Lock()
+	usePrimary := c.health.Healthy || time.Since(c.health.FailedAt) >= chainRetryInterval
+	c.mu.Unlock()
+
+	if usePrimary {
+		n, err := io.ReadFull(c.primary, p)
+		if err == nil {
+			c.mu.Lock()
+			c.health.Healthy = true
+			c.mu.Unlock()
+			return n, nil
+		}
+
+		c.mu.Lock()
+		c.health.Healthy = false
+		c.health.Failures++
+		c.health.LastError = err
+		c.health.FailedAt = time.Now()
+		c.mu.Unlock()
+	}
+
+	return io.ReadFull(c.fallback, p)
+}
+
+// Health returns the current health status of the source.
+func (c *ChainedSource) Health() SourceHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.health
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/it512/xtid"
+	"github.com/it512/xtid/xtidaudit"
+)
+
+func audit(args []string) {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	var in input
+	var a xtidaudit.Auditor
+	fs.StringVar(&in.format, "format", "lines", "input format: lines, csv or ndjson")
+	fs.StringVar(&in.column, "column", "", "column holding the IDs, by name or 0-based index")
+	fs.BoolVar(&a.Sorted, "sorted", false, "the IDs are sorted, detect duplicates without memory")
+	fs.IntVar(&a.Samples, "samples", xtidaudit.DefaultSamples, "samples shown for each kind of problem")
+	oldest := fs.String("min", "", "oldest valid time, RFC 3339")
+	newest := fs.String("max", "", "newest valid time, RFC 3339, or a duration from now such as 1h")
+	fs.Parse(args)
+
+	var err error
+	if *oldest != "" {
+		if a.Min, err = time.Parse(time.RFC3339, *oldest); err != nil {
+			log.Fatalf("-min: %v", err)
+		}
+	}
+	if *newest != "" {
+		if d, derr := time.ParseDuration(*newest); derr == nil {
+			a.Max = time.Now().Add(d)
+		} else if a.Max, err = time.Parse(time.RFC3339, *newest); err != nil {
+			log.Fatalf("-max: %v", err)
+		}
+	}
+
+	err = in.each(fs.Args(), func(_ string, _ int64, v string) error {
+		a.AddString(v)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := a.Report()
+	fmt.Printf("total:        %d\n", r.Total)
+	fmt.Printf("invalid:      %d\n", r.Invalid)
+	fmt.Printf("duplicates:   %d\n", r.Duplicates)
+	fmt.Printf("out of range: %d\n", r.OutOfRange)
+	if a.Sorted {
+		fmt.Printf("unordered:    %d\n", r.Unordered)
+	}
+	if !r.Oldest.IsZero() {
+		fmt.Printf("oldest:       %s\n", r.Oldest.UTC().Format(time.RFC3339Nano))
+		fmt.Printf("newest:       %s\n", r.Newest.UTC().Format(time.RFC3339Nano))
+	}
+	fmt.Println("types:")
+	for _, typ := range r.SortedTypes() {
+		name, _ := xtid.DefaultRegistry.Name(typ)
+		fmt.Printf("  %5d %-20s %d\n", typ, name, r.Types[typ])
+	}
+	for _, s := range r.InvalidSamples {
+		fmt.Printf("invalid: %q\n", s)
+	}
+	for _, id := range r.DuplicateSamples {
+		fmt.Printf("duplicate: %s\n", id)
+	}
+	for _, id := range r.OutOfRangeSamples {
+		fmt.Printf("out of range: %s %s\n", id, id.Time().UTC().Format(time.RFC3339Nano))
+	}
+
+	if !r.OK() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/it512/xtid/xtidsql"
+)
+
+func ddl(args []string) {
+	fs := flag.NewFlagSet("ddl", flag.ExitOnError)
+	dialect := fs.String("dialect", "postgres", "SQL dialect: postgres, mysql or sqlite")
+	column := fs.String("column", "id", "name of the ID column")
+	domain := fs.String("domain", xtidsql.DefaultDomain, "name of the Postgres domain")
+	table := fs.String("table", "", "name of a table to create, keyed by the ID column")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		log.Fatal("usage: xtid ddl [-dialect name] [-column name] [-domain name] [-table name]")
+	}
+
+	d, err := xtidsql.ParseDialect(*dialect)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cols, err := xtidsql.Columns(d, *column, *domain)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, stmt := range xtidsql.Preamble(d, *domain) {
+		fmt.Println(stmt + ";")
+	}
+	if *table == "" {
+		for _, col := range cols {
+			fmt.Println(col)
+		}
+		return
+	}
+	cols[0] += " PRIMARY KEY"
+	fmt.Printf("CREATE TABLE %s (\n\t%s\n);\n", *table, strings.Join(cols, ",\n\t"))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/it512/xtid/xtidgen"
+)
+
+func gentypes(args []string) {
+	fs := flag.NewFlagSet("gentypes", flag.ExitOnError)
+	out := fs.String("o", "", "output file, the standard output when empty")
+	pkg := fs.String("package", "", "package name, overriding the manifest")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: xtid gentypes [-o file] [-package name] manifest")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	m, err := xtidgen.Parse(data)
+	if err != nil {
+		log.Fatalf("%s: %v", fs.Arg(0), err)
+	}
+	if *pkg != "" {
+		m.Package = *pkg
+	}
+	src, err := xtidgen.Generate(m, filepath.Base(fs.Arg(0)))
+	if err != nil {
+		log.Fatalf("%s: %v", fs.Arg(0), err)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/it512/xtid/cmd/xtid
+
+go 1.23
+
+require (
+	github.com/it512/xtid v0.0.0-00010101000000-000000000000
+	github.com/it512/xtid/xtidgen v0.0.0-00010101000000-000000000000
+	github.com/lib/pq v1.10.9
+)
+
+require gopkg.in/yaml.v3 v3.0.1 // indirect
+
+replace (
+	github.com/it512/xtid => ../../
+	github.com/it512/xtid/xtidgen => ../../xtidgen
+)
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/it512/xtid"
+	"github.com/it512/xtid/xtidstats"
+)
+
+func hist(args []string) {
+	fs := flag.NewFlagSet("hist", flag.ExitOnError)
+	var in input
+	fs.StringVar(&in.format, "format", "lines", "input format: lines, csv or ndjson")
+	fs.StringVar(&in.column, "column", "", "column holding the IDs, by name or 0-based index")
+	interval := fs.Duration("interval", time.Minute, "length of the intervals")
+	fs.Parse(args)
+
+	h := xtidstats.NewHistogram(*interval)
+	var invalid int64
+	err := in.each(fs.Args(), func(_ string, _ int64, v string) error {
+		id, err := xtid.Parse(v)
+		if err != nil {
+			invalid++
+			return nil
+		}
+		h.Add(id)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	types := h.Types()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "time\ttotal\t")
+	for _, typ := range types {
+		fmt.Fprintf(w, "%s\t", xtid.TypeName(typ))
+	}
+	fmt.Fprintln(w)
+	for _, b := range h.Buckets() {
+		fmt.Fprintf(w, "%s\t%d\t", b.Start.Format(time.RFC3339), b.Count)
+		for _, typ := range types {
+			fmt.Fprintf(w, "%d\t", b.Types[typ])
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+	if invalid > 0 {
+		fmt.Fprintf(os.Stderr, "%d invalid IDs skipped\n", invalid)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// An input reads the values of a column from files, or the standard input.
+type input struct {
+	format string // lines, csv or ndjson
+	column string // name or 0-based index of the column, for csv and ndjson
+}
+
+// each calls fn with the value of the column on every record of the files,
+// along with the name of the file and the number of the record, starting at
+// 1. The standard input is read when there are no files, or for "-".
+func (in input) each(files []string, fn func(file string, n int64, value string) error) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, name := range files {
+		if err := in.readFile(name, func(n int64, v string) error { return fn(name, n, v) }); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (in input) readFile(name string, fn func(n int64, value string) error) error {
+	if name == "-" {
+		return in.read(os.Stdin, fn)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return in.read(f, fn)
+}
+
+func (in input) read(r io.Reader, fn func(n int64, value string) error) error {
+	switch in.format {
+	case "lines":
+		return readLines(r, fn)
+	case "csv":
+		return in.readCSV(r, fn)
+	case "ndjson":
+		if in.column == "" {
+			return fmt.Errorf("ndjson input requires a column")
+		}
+		return in.readNDJSON(r, fn)
+	default:
+		return fmt.Errorf("unknown format %q", in.format)
+	}
+}
+
+func readLines(r io.Reader, fn func(n int64, value string) error) error {
+	s := bufio.NewScanner(r)
+	var n int64
+	for s.Scan() {
+		n++
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		if err := fn(n, line); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+// readCSV reads the column given by name, or by index when it is a number,
+// the first one by default. Named columns are looked up in the header.
+func (in input) readCSV(r io.Reader, fn func(n int64, value string) error) error {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	cr.FieldsPerRecord = -1
+
+	col, err := strconv.Atoi(in.column)
+	var n int64
+	if in.column != "" && err != nil {
+		header, err := cr.Read()
+		if err != nil {
+			return err
+		}
+		n++
+		if col = slices.Index(header, in.column); col < 0 {
+			return fmt.Errorf("no column %q", in.column)
+		}
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		n++
+		if col >= len(rec) {
+			return fmt.Errorf("record %d: no column %d", n, col)
+		}
+		if err := fn(n, rec[col]); err != nil {
+			return err
+		}
+	}
+}
+
+func (in input) readNDJSON(r io.Reader, fn func(n int64, value string) error) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 16<<20)
+	var n int64
+	for s.Scan() {
+		n++
+		if len(strings.TrimSpace(s.Text())) == 0 {
+			continue
+		}
+		var rec map[string]json.RawMessage
+		if err := json.Unmarshal(s.Bytes(), &rec); err != nil {
+			return fmt.Errorf("record %d: %w", n, err)
+		}
+		var v string
+		if raw, ok := rec[in.column]; ok {
+			if err := json.Unmarshal(raw, &v); err != nil {
+				// Not a string, leave it to the caller to reject it
+				v = string(raw)
+			}
+		}
+		if err := fn(n, v); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/it512/xtid"
+)
+
+// Exit codes of xtid lint
+const (
+	lintOK      = 0
+	lintInvalid = 1
+	lintFailure = 2
+)
+
+// The number of values handed to a lint worker at once
+const lintBatch = 4096
+
+type lintValue struct {
+	file  string
+	index int // of the file, to order the errors
+	n     int64
+	value string
+}
+
+type lintError struct {
+	lintValue
+	err error
+}
+
+func lint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	var in input
+	fs.StringVar(&in.format, "format", "", "input format: lines, csv or ndjson, guessed from the file extension by default")
+	fs.StringVar(&in.column, "column", "", "column holding the IDs, by name or 0-based index")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "number of parallel workers")
+	samples := fs.Int("samples", 10, "number of errors shown")
+	allowEmpty := fs.Bool("allow-empty", false, "accept empty values")
+	fs.Parse(args)
+
+	if in.format == "" {
+		in.format = guessFormat(fs.Args())
+	}
+
+	batches := make(chan []lintValue, *workers)
+	var (
+		mu      sync.Mutex
+		invalid int64
+		errs    []lintError
+		total   int64
+	)
+	var wg sync.WaitGroup
+	for range max(*workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				for _, v := range batch {
+					if v.value == "" && *allowEmpty {
+						continue
+					}
+					_, err := xtid.Parse(v.value)
+					if err == nil {
+						continue
+					}
+					mu.Lock()
+					invalid++
+					errs = keepFirst(errs, lintError{v, err}, *samples)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	batch := make([]lintValue, 0, lintBatch)
+	index, last := -1, ""
+	err := in.each(fs.Args(), func(file string, n int64, value string) error {
+		if index < 0 || file != last {
+			index, last = index+1, file
+		}
+		total++
+		batch = append(batch, lintValue{file, index, n, value})
+		if len(batch) == lintBatch {
+			batches <- batch
+			batch = make([]lintValue, 0, lintBatch)
+		}
+		return nil
+	})
+	batches <- batch
+	close(batches)
+	wg.Wait()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "xtid lint:", err)
+		os.Exit(lintFailure)
+	}
+	for _, e := range errs {
+		fmt.Printf("%s:%d: %q: %v\n", e.file, e.n, e.value, e.err)
+	}
+	if invalid > 0 {
+		fmt.Printf("%d of %d IDs invalid\n", invalid, total)
+		os.Exit(lintInvalid)
+	}
+	os.Exit(lintOK)
+}
+
+// keepFirst adds e to errs, sorted by position, keeping only the first n.
+func keepFirst(errs []lintError, e lintError, n int) []lintError {
+	i := sort.Search(len(errs), func(i int) bool {
+		if errs[i].index != e.index {
+			return errs[i].index > e.index
+		}
+		return errs[i].n > e.n
+	})
+	if i >= n {
+		return errs
+	}
+	errs = append(errs, lintError{})
+	copy(errs[i+1:], errs[i:])
+	errs[i] = e
+	if len(errs) > n {
+		errs = errs[:n]
+	}
+	return errs
+}
+
+func guessFormat(files []string) string {
+	for _, f := range files {
+		switch filepath.Ext(f) {
+		case ".csv":
+			return "csv"
+		case ".ndjson", ".jsonl":
+			return "ndjson"
+		}
+	}
+	return "lines"
+}
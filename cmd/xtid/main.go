@@ -1,7 +1,43 @@
+// Command xtid prints a new XTID, or runs one of its subcommands:
+//
+//	xtid migrate -dsn postgres://... -table orders -key id -created-at created_at -type 7
+//	xtid audit -format csv -column id -sorted export.csv
+//
+// migrate rewrites the UUID or serial key of a Postgres table to XTIDs, see
+// package xtidmigrate. With -swap it prints the statements making the new
+// column the key of the table instead.
+//
+// audit reads IDs from files or the standard input, one per line or from a
+// CSV or NDJSON column, and reports invalid and duplicate IDs, IDs out of a
+// time range and the number of IDs of each type, see package xtidaudit. It
+// exits with status 1 when it finds problems.
+//
+// lint validates the IDs of a column in parallel, for data pipelines:
+//
+//	xtid lint -column id export.ndjson
+//
+// It prints the first errors, and exits with status 1 when it finds invalid
+// IDs, or 2 when it fails to read its input.
+//
+// hist counts the IDs of its input per time interval and type, from their
+// timestamps alone:
+//
+//	xtid hist -interval 5m ids.txt
+//
+// gentypes generates the Go declarations of the XTID types listed in a YAML
+// or JSON manifest, see package xtidgen:
+//
+//	xtid gentypes -o types_gen.go types.yaml
+//
+// ddl prints the recommended definitions of an ID column for a SQL dialect,
+// see package xtidsql, or the statement creating a table keyed by it:
+//
+//	xtid ddl -dialect mysql -column id -table events
 package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/it512/xtid"
 )
@@ -9,5 +45,27 @@ import (
 var id = xtid.IDGen(17)
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			migrate(os.Args[2:])
+			return
+		case "audit":
+			audit(os.Args[2:])
+			return
+		case "lint":
+			lint(os.Args[2:])
+			return
+		case "hist":
+			hist(os.Args[2:])
+			return
+		case "gentypes":
+			gentypes(os.Args[2:])
+			return
+		case "ddl":
+			ddl(os.Args[2:])
+			return
+		}
+	}
 	fmt.Println(id())
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/it512/xtid/xtidmigrate"
+	_ "github.com/lib/pq"
+)
+
+func migrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	var p xtidmigrate.Plan
+	dsn := fs.String("dsn", os.Getenv("DATABASE_URL"), "Postgres connection string, $DATABASE_URL by default")
+	fs.StringVar(&p.Table, "table", "", "table to migrate")
+	fs.StringVar(&p.Key, "key", "id", "UUID or serial key column")
+	fs.StringVar(&p.CreatedAt, "created-at", "", "creation time column, if any")
+	fs.StringVar(&p.Column, "column", "", "column of the new IDs, <key>_xtid by default")
+	fs.StringVar(&p.Mapping, "mapping", "", "mapping table, <table>_xtid_map by default")
+	fs.IntVar(&p.BatchSize, "batch", xtidmigrate.DefaultBatchSize, "rows per transaction")
+	typ := fs.Uint("type", 0, "type of the new IDs")
+	swap := fs.Bool("swap", false, "print the statements swapping the key columns and exit")
+	fs.Parse(args)
+
+	if *typ > 0xffff {
+		log.Fatalf("type %d out of range", *typ)
+	}
+	p.Type = uint16(*typ)
+
+	if *swap {
+		for _, stmt := range xtidmigrate.SwapStatements(p) {
+			fmt.Println(stmt + ";")
+		}
+		return
+	}
+
+	db, err := sql.Open("postgres", *dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	stats, err := xtidmigrate.Run(ctx, db, p, func(s xtidmigrate.Stats) {
+		log.Printf("migrated %d rows", s.Rows)
+	})
+	if err != nil {
+		log.Fatalf("after %d rows: %v", stats.Rows, err)
+	}
+	log.Printf("done, %d rows migrated", stats.Rows)
+}
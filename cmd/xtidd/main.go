@@ -0,0 +1,105 @@
+// Command xtidd is a small HTTP service minting XTIDs for programs that
+// cannot embed the Go library.
+//
+//	GET /new?type=42&count=100   mint IDs, returned as {"ids": [...]}
+//	GET /healthz                 liveness probe
+//	GET /metrics                 Prometheus text exposition
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/it512/xtid"
+)
+
+var (
+	addr     = flag.String("addr", ":8080", "listen address")
+	maxCount = flag.Int("max-count", 1000, "maximum number of IDs per request")
+)
+
+var (
+	requests  atomic.Uint64
+	generated atomic.Uint64
+	failures  atomic.Uint64
+)
+
+type response struct {
+	IDs []string `json:"ids"`
+}
+
+func main() {
+	flag.Parse()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/new", handleNew)
+	mux.HandleFunc("/healthz", handleHealth)
+	mux.HandleFunc("/metrics", handleMetrics)
+
+	log.Printf("xtidd listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func handleNew(w http.ResponseWriter, r *http.Request) {
+	requests.Add(1)
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+
+	typ, err := uintParam(q.Get("type"), 0, 16)
+	if err != nil {
+		http.Error(w, "invalid type: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	count, err := uintParam(q.Get("count"), 1, 32)
+	if err != nil || count == 0 || count > uint64(*maxCount) {
+		http.Error(w, fmt.Sprintf("count must be between 1 and %d", *maxCount), http.StatusBadRequest)
+		return
+	}
+
+	resp := response{IDs: make([]string, count)}
+	for i := range resp.IDs {
+		id, err := xtid.NewWithType(uint16(typ))
+		if err != nil {
+			failures.Add(1)
+			http.Error(w, "generation failed", http.StatusInternalServerError)
+			return
+		}
+		resp.IDs[i] = id.String()
+	}
+	generated.Add(count)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	if _, err := xtid.NewWithType(0); err != nil {
+		http.Error(w, "entropy source unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# TYPE xtidd_requests_total counter\nxtidd_requests_total %d\n", requests.Load())
+	fmt.Fprintf(w, "# TYPE xtidd_ids_generated_total counter\nxtidd_ids_generated_total %d\n", generated.Load())
+	fmt.Fprintf(w, "# TYPE xtidd_generation_failures_total counter\nxtidd_generation_failures_total %d\n", failures.Load())
+}
+
+func uintParam(s string, def uint64, bits int) (uint64, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.ParseUint(s, 10, bits)
+}
@@ -0,0 +1,59 @@
+package xtid
+
+import (
+	"io"
+)
+
+// An Encoder writes string-encoded XTIDs into buffers owned by the caller,
+// for serialization layers which can't afford an allocation per ID, such as
+// binary protocols or columnar writers. It holds its own scratch space,
+// which makes EncodeTo allocation free, so an Encoder must not be used
+// concurrently. The zero value is ready to use.
+type Encoder struct {
+	buf [stringEncodedLength]byte
+}
+
+// Encode writes the 27 bytes of the string encoding of id at the start of
+// dst, and returns their number.
+func (e *Encoder) Encode(dst []byte, id XTID) (int, error) {
+	if len(dst) < stringEncodedLength {
+		return 0, errShortBuffer
+	}
+	fastEncodeBase62(dst[:stringEncodedLength], id[:])
+	return stringEncodedLength, nil
+}
+
+// EncodeTo writes the string encoding of id to w.
+func (e *Encoder) EncodeTo(w io.Writer, id XTID) (int, error) {
+	fastEncodeBase62(e.buf[:], id[:])
+	return w.Write(e.buf[:])
+}
+
+// A Decoder reads string-encoded XTIDs from buffers owned by the caller,
+// the counterpart of Encoder. It holds its own scratch space, which makes
+// DecodeFrom allocation free, so a Decoder must not be used concurrently.
+// The zero value is ready to use.
+type Decoder struct {
+	buf [stringEncodedLength]byte
+}
+
+// Decode decodes the 27 bytes of src into dst, rejecting invalid IDs like
+// Parse. dst is left untouched on failure.
+func (d *Decoder) Decode(dst *XTID, src []byte) error {
+	var id XTID
+	if err := parseInto(&id, src); err != nil {
+		return err
+	}
+	*dst = id
+	return nil
+}
+
+// DecodeFrom reads the 27 bytes of a string-encoded XTID from r and decodes
+// them into dst. It returns io.EOF when r has no more bytes, and
+// io.ErrUnexpectedEOF when it ends within an ID.
+func (d *Decoder) DecodeFrom(r io.Reader, dst *XTID) error {
+	if _, err := io.ReadFull(r, d.buf[:]); err != nil {
+		return err
+	}
+	return d.Decode(dst, d.buf[:])
+}
@@ -0,0 +1,259 @@
+package xtid
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+	"time"
+)
+
+const (
+	// Timestamp is a uint32 of seconds
+	compactTimestampLengthInBytes = 4
+
+	// Compact XTIDs are 16 bytes when binary encoded
+	compactByteLength = compactTimestampLengthInBytes + typeLengthInbytes + payloadLengthInBytes
+
+	compactPayloadStart = compactTimestampLengthInBytes + typeLengthInbytes
+
+	// The length of a compact XTID when string (base62) encoded
+	compactStringEncodedLength = 22
+)
+
+// Compact XTIDs are 16 bytes:
+//
+// 00-03 byte: uint32 timestamp in seconds since the Unix epoch
+// 04-05 byte: uint16 type
+// 06-15 byte: random "payload"
+//
+// They trade the timestamp precision of XTIDs for size, for storage where
+// every byte counts, and remain sortable by time and type-tagged. Their
+// timestamps run out in 2106.
+type Compact [compactByteLength]byte
+
+var (
+	errCompactSize    = fmt.Errorf("Valid compact XTIDs are %v bytes", compactByteLength)
+	errCompactStrSize = fmt.Errorf("Valid encoded compact XTIDs are %v characters", compactStringEncodedLength)
+	errCompactStrVal  = fmt.Errorf("Valid encoded compact XTIDs are bounded by %s and %s", zeroString[:compactStringEncodedLength], "7n42DGM5Tflk9n8mt7Fhc7")
+	errCompactTime    = fmt.Errorf("Compact XTID timestamps must fit in 32 bits")
+)
+
+// MakeCompact makes a new compact XTID using custom time and type.
+func MakeCompact(t time.Time, typ uint16) (c Compact, err error) {
+	sec := t.Unix()
+	if sec < 0 || sec > 1<<32-1 {
+		return c, errCompactTime
+	}
+
+	if _, err = io.ReadFull(loadSourceForType(typ), c[compactPayloadStart:]); err != nil {
+		return Compact{}, &EntropyError{Err: err}
+	}
+
+	binary.BigEndian.PutUint32(c[:compactTimestampLengthInBytes], uint32(sec))
+	binary.BigEndian.PutUint16(c[compactTimestampLengthInBytes:compactPayloadStart], typ)
+	return
+}
+
+// NewCompactWithType makes a new compact XTID of type typ stamped with the
+// current time.
+func NewCompactWithType(typ uint16) (Compact, error) {
+	return MakeCompact(time.Now(), typ)
+}
+
+// Time returns the timestamp of the ID.
+func (c Compact) Time() time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint32(c[:compactTimestampLengthInBytes])), 0)
+}
+
+// Type returns the type of the ID.
+func (c Compact) Type() uint16 {
+	return binary.BigEndian.Uint16(c[compactTimestampLengthInBytes:compactPayloadStart])
+}
+
+// Bytes returns the raw byte representation of the ID.
+func (c Compact) Bytes() []byte {
+	return c[:]
+}
+
+// IsNil returns true if this is an all-zero ID.
+func (c Compact) IsNil() bool {
+	return c == Compact{}
+}
+
+// String returns the 22 characters base62 representation of the ID, which
+// can be passed through ParseCompact.
+func (c Compact) String() string {
+	var dst [compactStringEncodedLength]byte
+	encodeCompactBase62(dst[:], c[:])
+	return string(dst[:])
+}
+
+// XTID converts the ID to a full XTID with the same time, type and payload.
+func (c Compact) XTID() XTID {
+	var id XTID
+	binary.BigEndian.PutUint64(id[:timestampLengthInBytes], timeToCorrectedUTCTimestamp(c.Time()))
+	copy(id[timestampLengthInBytes:], c[compactTimestampLengthInBytes:])
+	return id
+}
+
+// ToCompact converts id to a compact XTID, truncating its timestamp to the
+// second. It fails if the timestamp does not fit in a compact XTID.
+func ToCompact(id XTID) (Compact, error) {
+	var c Compact
+
+	sec := id.Time().Unix()
+	if sec < 0 || sec > 1<<32-1 {
+		return c, errCompactTime
+	}
+
+	binary.BigEndian.PutUint32(c[:compactTimestampLengthInBytes], uint32(sec))
+	copy(c[compactTimestampLengthInBytes:], id[timestampLengthInBytes:])
+	return c, nil
+}
+
+// ParseCompact decodes a string-encoded representation of a compact XTID.
+func ParseCompact(s string) (Compact, error) {
+	var c Compact
+	if len(s) != compactStringEncodedLength {
+		return c, errCompactStrSize
+	}
+	if err := decodeCompactBase62(c[:], s); err != nil {
+		return Compact{}, errCompactStrVal
+	}
+	return c, nil
+}
+
+// CompactFromBytes constructs a compact XTID from its 16-byte binary
+// representation.
+func CompactFromBytes(b []byte) (Compact, error) {
+	var c Compact
+	if len(b) != compactByteLength {
+		return c, errCompactSize
+	}
+	copy(c[:], b)
+	return c, nil
+}
+
+func (c Compact) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Compact) UnmarshalText(b []byte) error {
+	id, err := ParseCompact(string(b))
+	if err != nil {
+		return err
+	}
+	*c = id
+	return nil
+}
+
+func (c Compact) MarshalBinary() ([]byte, error) {
+	return c.Bytes(), nil
+}
+
+func (c *Compact) UnmarshalBinary(b []byte) error {
+	id, err := CompactFromBytes(b)
+	if err != nil {
+		return err
+	}
+	*c = id
+	return nil
+}
+
+// Value converts the ID into a SQL driver value.
+func (c Compact) Value() (driver.Value, error) {
+	if c.IsNil() {
+		return nil, nil
+	}
+	return c.String(), nil
+}
+
+// Scan implements the sql.Scanner interface. It supports converting from
+// string, []byte, or nil into a compact XTID value.
+func (c *Compact) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*c = Compact{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("Scan: unable to scan type %T into compact XTID", v)
+	}
+
+	switch len(b) {
+	case 0:
+		*c = Compact{}
+		return nil
+	case compactByteLength:
+		return c.UnmarshalBinary(b)
+	case compactStringEncodedLength:
+		return c.UnmarshalText(b)
+	default:
+		return errCompactSize
+	}
+}
+
+// Encodes the 16 bytes of src as 22 base62 digits into dst, like
+// fastEncodeBase62: the 128-bit value is divided twice by 62^10, which
+// leaves 2 digits.
+func encodeCompactBase62(dst []byte, src []byte) {
+	// These lines help BCE (Bounds Check Elimination).
+	_ = dst[21]
+	_ = src[15]
+
+	hi := binary.BigEndian.Uint64(src[0:8])
+	lo := binary.BigEndian.Uint64(src[8:16])
+
+	var r uint64
+	hi, r = bits.Div64(0, hi, base62Pow10)
+	lo, r = bits.Div64(r, lo, base62Pow10)
+	encodeBase62Digits(dst[12:22], r)
+
+	hi, r = bits.Div64(0, hi, base62Pow10)
+	lo, r = bits.Div64(r, lo, base62Pow10)
+	encodeBase62Digits(dst[2:12], r)
+
+	// What remains is below 2^128 / 62^20, less than 62^2
+	encodeBase62Digits(dst[0:2], lo)
+}
+
+// Decodes the 22 base62 digits of src into the 16 bytes of dst, like
+// decodeBase62Limbs, failing on invalid digits or values overflowing 128
+// bits.
+func decodeCompactBase62(dst []byte, src string) error {
+	// These lines help BCE (Bounds Check Elimination).
+	_ = dst[15]
+	_ = src[21]
+
+	var invalid byte
+	c0 := decodeBase62Group(src[0:2], &invalid)
+	c1 := decodeBase62Group(src[2:12], &invalid)
+	c2 := decodeBase62Group(src[12:22], &invalid)
+	if invalid != 0 {
+		return errStrChar
+	}
+
+	// (c0 * 62^10 + c1) * 62^10 + c2, where c0 * 62^10 is below 2^72
+	var carry uint64
+	th, tl := bits.Mul64(c0, base62Pow10)
+	tl, carry = bits.Add64(tl, c1, 0)
+	th += carry
+
+	h1, lo := bits.Mul64(tl, base62Pow10)
+	h2, l2 := bits.Mul64(th, base62Pow10)
+	lo, carry = bits.Add64(lo, c2, 0)
+	hi, carry := bits.Add64(h1, l2, carry)
+	if h2 != 0 || carry != 0 {
+		return errStrValue
+	}
+
+	binary.BigEndian.PutUint64(dst[0:8], hi)
+	binary.BigEndian.PutUint64(dst[8:16], lo)
+	return nil
+}
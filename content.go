@@ -0,0 +1,36 @@
+package xtid
+
+import (
+	"crypto/sha256"
+	"io"
+	"time"
+)
+
+// FromContent returns a XTID of type typ stamped with t whose payload is the
+// first 10 bytes of the SHA-256 digest of the content read from r, for blob
+// stores wanting identifiers sorted by time yet tied to the content. Blobs
+// with the same content get the same payload whenever they are stored, see
+// SameContent. The time must not be before the epoch.
+//
+// Ten bytes of digest make accidental collisions negligible, but not
+// deliberate ones: don't rely on them to tell apart content from untrusted
+// sources.
+func FromContent(t time.Time, typ uint16, r io.Reader) (XTID, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return Nil, err
+	}
+	return MakeWithPayload(t, typ, [payloadLengthInBytes]byte(h.Sum(nil))), nil
+}
+
+// FromContentBytes is like FromContent, for content held in memory.
+func FromContentBytes(t time.Time, typ uint16, content []byte) XTID {
+	sum := sha256.Sum256(content)
+	return MakeWithPayload(t, typ, [payloadLengthInBytes]byte(sum[:]))
+}
+
+// SameContent reports whether two IDs minted by FromContent have the same
+// payload, that is most likely identify the same content.
+func SameContent(a, b XTID) bool {
+	return a.Payload() == b.Payload()
+}
@@ -0,0 +1,36 @@
+package xtid
+
+import (
+	"context"
+)
+
+type generatorKey struct{}
+
+type idKey struct{}
+
+// NewContext returns a copy of ctx carrying gen, for code further down the
+// call chain to mint IDs with.
+func NewContext(ctx context.Context, gen *Generator) context.Context {
+	return context.WithValue(ctx, generatorKey{}, gen)
+}
+
+// FromContext returns the generator carried by ctx, or the generator behind
+// the package-level constructors when there is none.
+func FromContext(ctx context.Context) *Generator {
+	if gen, ok := ctx.Value(generatorKey{}).(*Generator); ok && gen != nil {
+		return gen
+	}
+	return defaultGenerator
+}
+
+// NewIDContext returns a copy of ctx carrying id, typically the ID of the
+// request or job being processed.
+func NewIDContext(ctx context.Context, id XTID) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// IDFromContext returns the ID carried by ctx, if any.
+func IDFromContext(ctx context.Context) (XTID, bool) {
+	id, ok := ctx.Value(idKey{}).(XTID)
+	return id, ok
+}
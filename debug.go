@@ -0,0 +1,44 @@
+package xtid
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number of payload bytes shown by DebugString
+const debugPayloadBytes = 2
+
+// DebugString returns the string representation of the ID annotated with
+// its time, type and the first bytes of its payload, for error messages
+// and support tooling:
+//
+//	00DdjxnD9QhSu715j5vwl3AE8YK (t=2026-10-16T02:50:37.123456Z type=42/user payload=9f3a…)
+//
+// The type is followed by its name in DefaultRegistry, when registered.
+func (i XTID) DebugString() string {
+	var b strings.Builder
+	b.WriteString(i.String())
+	switch {
+	case i.IsNil():
+		b.WriteString(" (nil)")
+		return b.String()
+	case i == Max:
+		b.WriteString(" (max)")
+		return b.String()
+	}
+
+	b.WriteString(" (t=")
+	b.WriteString(i.Time().UTC().Format(time.RFC3339Nano))
+	b.WriteString(" type=")
+	b.WriteString(strconv.Itoa(int(i.Type())))
+	if name, ok := DefaultRegistry.Name(i.Type()); ok {
+		b.WriteByte('/')
+		b.WriteString(name)
+	}
+	b.WriteString(" payload=")
+	b.WriteString(hex.EncodeToString(i[payloadStart : payloadStart+debugPayloadBytes]))
+	b.WriteString("…)")
+	return b.String()
+}
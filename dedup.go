@@ -0,0 +1,139 @@
+package xtid
+
+import (
+	"sync"
+	"time"
+)
+
+// A DedupWindow remembers the IDs of the messages seen within a time window,
+// for at-least-once consumers which key idempotency on the XTIDs of events:
+//
+//	w := xtid.NewDedupWindow(10*time.Minute, 10)
+//	if !w.Add(msg.ID) {
+//		return // redelivered
+//	}
+//
+// The IDs are kept in sets spanning a fraction of the window each, which are
+// dropped as the window moves. The window follows the timestamps of the IDs
+// themselves, not the clock: it ends with the newest ID added, so that the
+// memory held doesn't depend on when messages are consumed. An ID minted far
+// in the future thus empties the window. It is safe for concurrent use.
+type DedupWindow struct {
+	mu sync.Mutex
+	// Length of a bucket in microseconds
+	span int64
+	sets []map[XTID]struct{}
+	// The bucket held by each set
+	buckets []int64
+	newest  int64
+	started bool
+}
+
+// NewDedupWindow returns a DedupWindow covering window, split into the given
+// number of buckets. IDs are forgotten a whole bucket at a time, so that the
+// window actually covers between window minus a bucket and window.
+func NewDedupWindow(window time.Duration, buckets int) *DedupWindow {
+	buckets = max(buckets, 1)
+	return &DedupWindow{
+		span:    max(window.Microseconds()/int64(buckets), 1),
+		sets:    make([]map[XTID]struct{}, buckets),
+		buckets: make([]int64, buckets),
+	}
+}
+
+// bucket returns the bucket of id, keyed on its raw timestamp so that any
+// valid ID, however far in the future, has one.
+func (w *DedupWindow) bucket(id XTID) int64 {
+	return id.micros() / w.span
+}
+
+// slot returns the index of the set holding bucket b.
+func (w *DedupWindow) slot(b int64) int {
+	n := int64(len(w.sets))
+	return int((b%n + n) % n)
+}
+
+// expired reports whether bucket b is before the window. The caller must
+// hold w.mu.
+func (w *DedupWindow) expired(b int64) bool {
+	return w.started && b <= w.newest-int64(len(w.sets))
+}
+
+// set returns the set of bucket b, or nil if it holds no ID. The caller must
+// hold w.mu.
+func (w *DedupWindow) set(b int64) map[XTID]struct{} {
+	j := w.slot(b)
+	if w.sets[j] == nil || w.buckets[j] != b {
+		return nil
+	}
+	return w.sets[j]
+}
+
+// Add records id and reports whether it is new, that is it wasn't added
+// within the window. IDs older than the window can't be checked and are
+// reported as not new, see Expired.
+func (w *DedupWindow) Add(id XTID) bool {
+	b := w.bucket(id)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.expired(b) {
+		return false
+	}
+	if !w.started || b > w.newest {
+		w.newest, w.started = b, true
+	}
+
+	s := w.set(b)
+	if s == nil {
+		// The slot is free, or holds a bucket which left the window
+		j := w.slot(b)
+		s = make(map[XTID]struct{})
+		w.sets[j], w.buckets[j] = s, b
+	}
+	if _, ok := s[id]; ok {
+		return false
+	}
+	s[id] = struct{}{}
+	return true
+}
+
+// Contains reports whether id was added within the window.
+func (w *DedupWindow) Contains(id XTID) bool {
+	b := w.bucket(id)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.expired(b) {
+		return false
+	}
+	_, ok := w.set(b)[id]
+	return ok
+}
+
+// Expired reports whether id is older than the window, in which case Add
+// can't tell whether it was seen.
+func (w *DedupWindow) Expired(id XTID) bool {
+	b := w.bucket(id)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.expired(b)
+}
+
+// Len returns the number of IDs within the window.
+func (w *DedupWindow) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := 0
+	for j, s := range w.sets {
+		if s != nil && !w.expired(w.buckets[j]) {
+			n += len(s)
+		}
+	}
+	return n
+}
@@ -0,0 +1,75 @@
+package xtid
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDedupWindow(t *testing.T) {
+	t0 := time.Now()
+	at := func(d time.Duration) XTID {
+		return Must(defaultGenerator.Make(t0.Add(d), 1))
+	}
+	w := NewDedupWindow(10*time.Minute, 10)
+
+	a, b := at(0), at(5*time.Minute)
+	if !w.Add(a) || !w.Add(b) {
+		t.Fatal("new IDs reported as seen")
+	}
+	if w.Add(a) || !w.Contains(a) || w.Len() != 2 {
+		t.Fatalf("Add, Contains, Len = %v, %v, %d after redelivery", w.Add(a), w.Contains(a), w.Len())
+	}
+
+	// IDs older than the newest one within the window are still checked
+	late := at(-3 * time.Minute)
+	if !w.Add(late) || w.Add(late) {
+		t.Error("late ID within the window not deduplicated")
+	}
+
+	// The window moves with the newest ID, and a leaves it
+	c := at(11 * time.Minute)
+	if !w.Add(c) {
+		t.Fatal("new ID reported as seen")
+	}
+	if !w.Expired(a) || w.Contains(a) || w.Add(a) {
+		t.Error("ID before the window still checked")
+	}
+	if w.Expired(b) || !w.Contains(b) {
+		t.Error("ID within the window forgotten")
+	}
+	if n := w.Len(); n != 2 {
+		t.Errorf("Len = %d, want 2", n)
+	}
+
+	// An ID far in the future empties the window
+	if !w.Add(at(24 * time.Hour)) {
+		t.Fatal("new ID reported as seen")
+	}
+	if n := w.Len(); n != 1 {
+		t.Errorf("Len = %d, want 1", n)
+	}
+}
+
+func TestDedupWindowConcurrent(t *testing.T) {
+	w := NewDedupWindow(time.Minute, 4)
+	ids := testIDs(1000)
+	var added atomic.Int32
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, id := range ids {
+				if w.Add(id) {
+					added.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := added.Load(); n != int32(len(ids)) {
+		t.Errorf("%d IDs added, want %d", n, len(ids))
+	}
+}
@@ -0,0 +1,95 @@
+package xtid
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// The most IDs ReadDelta allocates room for upfront, whatever the count
+// announced by the stream
+const deltaMaxPrealloc = 1 << 16
+
+var (
+	errDeltaUnsorted = errors.New("xtid: delta encoded IDs must be sorted")
+	errDeltaCorrupt  = errors.New("xtid: corrupt delta encoded IDs")
+)
+
+// WriteDelta writes ids, which must be sorted as by Compare, to w in a
+// compact form where timestamps are stored as deltas from the previous one:
+// a uvarint count of IDs, then for each of them the uvarint timestamp delta,
+// the uvarint XOR of its type with the previous one, and the 10-byte payload.
+// IDs minted close in time and of a same type thus take 12 bytes instead of
+// 20. It returns the number of bytes written.
+func WriteDelta(w io.Writer, ids []XTID) (int64, error) {
+	for j := 1; j < len(ids); j++ {
+		if Compare(ids[j-1], ids[j]) > 0 {
+			return 0, errDeltaUnsorted
+		}
+	}
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	var buf [2*binary.MaxVarintLen64 + payloadLengthInBytes]byte
+
+	n := binary.PutUvarint(buf[:], uint64(len(ids)))
+	bw.Write(buf[:n])
+
+	var prevTs uint64
+	var prevTyp uint16
+	for _, id := range ids {
+		ts, typ := id.rawTimestamp(), id.Type()
+		n := binary.PutUvarint(buf[:], ts-prevTs)
+		n += binary.PutUvarint(buf[n:], uint64(typ^prevTyp))
+		n += copy(buf[n:], id[payloadStart:])
+		if _, err := bw.Write(buf[:n]); err != nil {
+			return cw.n, err
+		}
+		prevTs, prevTyp = ts, typ
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ReadDelta reads IDs written by WriteDelta from r. As reads are buffered, r
+// may be read past the end of the IDs.
+func ReadDelta(r io.Reader) ([]XTID, error) {
+	br := bufio.NewReader(r)
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	ids := make([]XTID, 0, min(count, deltaMaxPrealloc))
+	var ts uint64
+	var typ uint16
+	for ; count > 0; count-- {
+		delta, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		x, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if x > 0xffff {
+			return nil, errDeltaCorrupt
+		}
+		ts += delta
+		typ ^= uint16(x)
+
+		var b [byteLength]byte
+		binary.BigEndian.PutUint64(b[:timestampLengthInBytes], ts)
+		binary.BigEndian.PutUint16(b[timestampLengthInBytes:payloadStart], typ)
+		if _, err := io.ReadFull(br, b[payloadStart:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		id, err := FromBytes(b[:])
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,76 @@
+package xtid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestDelta(t *testing.T) {
+	for _, n := range []int{0, 1, 1000} {
+		ids := testIDs(n)
+		var buf bytes.Buffer
+		written, err := WriteDelta(&buf, ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written != int64(buf.Len()) {
+			t.Errorf("%d IDs: WriteDelta = %d, wrote %d bytes", n, written, buf.Len())
+		}
+		// A millisecond apart, timestamp deltas but the first take 2 bytes
+		if max := 3 + binary.MaxVarintLen64 + n*(2+1+payloadLengthInBytes); buf.Len() > max {
+			t.Errorf("%d IDs take %d bytes, want at most %d", n, buf.Len(), max)
+		}
+
+		got, err := ReadDelta(&buf)
+		if err != nil {
+			t.Fatalf("%d IDs: %v", n, err)
+		}
+		if !slices.Equal(got, ids) {
+			t.Errorf("%d IDs: read %d different IDs", n, len(got))
+		}
+	}
+
+	// Duplicates are sorted too
+	ids := testIDs(2)
+	ids = append(ids, ids[1])
+	var buf bytes.Buffer
+	if _, err := WriteDelta(&buf, ids); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadDelta(&buf); err != nil || !slices.Equal(got, ids) {
+		t.Errorf("ReadDelta = %v, %v, want %v", got, err, ids)
+	}
+}
+
+func TestDeltaErrors(t *testing.T) {
+	ids := testIDs(3)
+	if _, err := WriteDelta(io.Discard, []XTID{ids[1], ids[0]}); !errors.Is(err, errDeltaUnsorted) {
+		t.Errorf("WriteDelta of unsorted IDs = %v, want %v", err, errDeltaUnsorted)
+	}
+
+	var buf bytes.Buffer
+	if _, err := WriteDelta(&buf, ids); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	for _, l := range []int{0, 1, 2, len(b) - 1} {
+		if _, err := ReadDelta(bytes.NewReader(b[:l])); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("ReadDelta of %d bytes = %v, want %v", l, err, io.ErrUnexpectedEOF)
+		}
+	}
+
+	// One ID whose type delta doesn't fit in 16 bits
+	if _, err := ReadDelta(bytes.NewReader([]byte{1, 0, 0x80, 0x80, 0x04})); !errors.Is(err, errDeltaCorrupt) {
+		t.Errorf("ReadDelta of a corrupt type = %v, want %v", err, errDeltaCorrupt)
+	}
+
+	// A huge announced count doesn't allocate upfront
+	huge := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+	if _, err := ReadDelta(bytes.NewReader(huge)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadDelta of a huge count = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
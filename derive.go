@@ -0,0 +1,35 @@
+package xtid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+)
+
+// Derive returns the XTID of type typ named name within namespace, like
+// UUIDv5 does for UUIDs: the same namespace, name and type always yield the
+// same ID, which makes creating entities from external natural keys
+// idempotent. The payload is taken from an HMAC-SHA256 of typ and name keyed
+// by namespace, and the timestamp is that of namespace, so that all the IDs
+// derived within a namespace share it. Use DeriveAt to pick the time.
+func Derive(namespace XTID, name []byte, typ uint16) XTID {
+	return DeriveAt(namespace.Time(), namespace, name, typ)
+}
+
+// DeriveAt is like Derive, with IDs stamped with t, which must not be before
+// the epoch. Callers must pass the same t to derive the same ID again, such
+// as the creation time of the entity named name.
+func DeriveAt(t time.Time, namespace XTID, name []byte, typ uint16) XTID {
+	return MakeWithPayload(t, typ, derivePayload(namespace, name, typ))
+}
+
+func derivePayload(namespace XTID, name []byte, typ uint16) (payload [payloadLengthInBytes]byte) {
+	mac := hmac.New(sha256.New, namespace[:])
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], typ)
+	mac.Write(b[:])
+	mac.Write(name)
+	copy(payload[:], mac.Sum(nil))
+	return
+}
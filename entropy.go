@@ -1,29 +1,134 @@
 package xtid
 
 import (
-	"bufio"
 	"crypto/rand"
+	"errors"
+	"fmt"
 	"io"
 	"sync"
 )
 
+// Default size of the chunks of random bytes read from crypto/rand at once
+const entropyChunkSize = 4096
+
+// ErrEntropy matches, through errors.Is, every error caused by a failure to
+// read from the source of random bytes.
+var ErrEntropy = errors.New("xtid: entropy source failure")
+
+// EntropyError is returned when an ID cannot be minted because reading from
+// the source of random bytes failed.
+type EntropyError struct {
+	Err error
+}
+
+func (e *EntropyError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrEntropy, e.Err)
+}
+
+func (e *EntropyError) Unwrap() error {
+	return e.Err
+}
+
+func (e *EntropyError) Is(target error) bool {
+	return target == ErrEntropy
+}
+
+// A PayloadSource fills the random payload of an ID in one call. Generators
+// prefer it over Read when their source implements it, which saves the
+// io.ReadFull loop and lets sources such as counters or userspace generators
+// write the 10 bytes in place. The default source and ChaCha8Source
+// implement it.
+//
+// FillPayload must fill all of p, or fail.
+type PayloadSource interface {
+	FillPayload(p *[payloadLengthInBytes]byte) error
+}
+
+// EntropyFunc adapts a function filling a buffer with random bytes to the
+// io.Reader expected by SetSource, for platforms where the entropy comes
+// from a host call, such as crypto.getRandomValues in browsers:
+//
+//	xtid.SetSource(xtid.EntropyFunc(func(p []byte) error {
+//		js.CopyBytesToGo(p, getRandomValues(len(p)))
+//		return nil
+//	}))
+type EntropyFunc func(p []byte) error
+
+func (f EntropyFunc) Read(p []byte) (int, error) {
+	if err := f(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+type entropyBuffer struct {
+	buf []byte
+	off int
+}
+
+// entropyPool buffers a source in per-P chunks handed out by a sync.Pool,
+// so that concurrent readers don't contend on a single lock.
 type entropyPool struct {
-	mux    sync.Mutex
-	buffer io.Reader
+	src  io.Reader
+	pool sync.Pool
 }
 
 func newEntropyPool() *entropyPool {
+	return newBufferedSource(rand.Reader, entropyChunkSize)
+}
+
+func newBufferedSource(src io.Reader, size int) *entropyPool {
 	return &entropyPool{
-		buffer: bufio.NewReader(rand.Reader),
+		src: src,
+		pool: sync.Pool{
+			New: func() any {
+				return &entropyBuffer{buf: make([]byte, size), off: size}
+			},
+		},
+	}
+}
+
+// BufferedSource returns a source reading src in chunks of size bytes, which
+// it hands out in smaller reads. The default source is crypto/rand buffered
+// in 4 KiB chunks; services minting IDs at high rates may want larger ones:
+//
+//	xtid.SetSource(xtid.BufferedSource(rand.Reader, 64<<10))
+//
+// Buffered bytes are dropped on read errors, which are reported as is.
+func BufferedSource(src io.Reader, size int) io.Reader {
+	if size < payloadLengthInBytes {
+		size = payloadLengthInBytes
 	}
+	return newBufferedSource(src, size)
 }
 
 func (r *entropyPool) Read(p []byte) (n int, err error) {
-	r.mux.Lock()
-	defer r.mux.Unlock()
-	return r.buffer.Read(p)
+	b := r.pool.Get().(*entropyBuffer)
+	defer r.pool.Put(b)
+
+	for n < len(p) {
+		if b.off == len(b.buf) {
+			if _, err = io.ReadFull(r.src, b.buf); err != nil {
+				return
+			}
+			b.off = 0
+		}
+
+		c := copy(p[n:], b.buf[b.off:])
+		// Don't keep handed out bytes around in memory
+		clear(b.buf[b.off : b.off+c])
+		b.off += c
+		n += c
+	}
+
+	return
+}
+
+func (r *entropyPool) FillPayload(p *[payloadLengthInBytes]byte) error {
+	_, err := r.Read(p[:])
+	return err
 }
 
 func init() {
-	SetSource(newEntropyPool())
+	SetSource(defaultSource())
 }
@@ -0,0 +1,77 @@
+package xtid
+
+import (
+	"errors"
+	"time"
+)
+
+// The XTID epoch, in nanoseconds since the Unix epoch. Timestamps count the
+// time elapsed since it.
+var epoch int64
+
+// SetEpoch sets the epoch from which the timestamps of XTIDs minted and
+// decoded by the package count, in place of the Unix epoch. Like SetSource,
+// it should be called once at startup, and every program exchanging XTIDs
+// must agree on it: the epoch is not recorded in the IDs themselves. Passing
+// the zero Time restores the Unix epoch.
+//
+//	xtid.SetEpoch(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+func SetEpoch(t time.Time) {
+	if t.IsZero() {
+		epoch = 0
+		return
+	}
+	epoch = t.UnixNano()
+}
+
+// Epoch returns the epoch set with SetEpoch.
+func Epoch() time.Time {
+	return time.Unix(0, epoch).UTC()
+}
+
+// WithEpoch makes the generator count timestamps from e instead of the
+// package epoch. Use TimeFrom to decode the time of the IDs it mints.
+func WithEpoch(e time.Time) Option {
+	return func(g *Generator) error {
+		ns := e.UnixNano()
+		g.epoch = &ns
+		return nil
+	}
+}
+
+// TimeFrom returns the time of the ID, assuming it counts from epoch e
+// rather than from the package epoch.
+func (i XTID) TimeFrom(e time.Time) time.Time {
+	return timestampToTime(i.rawTimestamp(), e.UnixNano())
+}
+
+var errEpochRange = errors.New("xtid: time out of range for the target epoch")
+
+// ConvertEpoch rewrites the timestamp of id, minted counting from epoch
+// from, to count from epoch to instead. The time, type and payload of the ID
+// are preserved.
+func ConvertEpoch(id XTID, from, to time.Time) (XTID, error) {
+	t := id.TimeFrom(from)
+	if t.Before(to) {
+		return Nil, errEpochRange
+	}
+	ts := timeToTimestamp(t, to.UnixNano(), id.Precision() == time.Nanosecond)
+	return id.withRawTimestamp(ts), nil
+}
+
+// timeToTimestamp converts t to a timestamp counting from epoch e, in
+// nanoseconds since the Unix epoch, including the version bits.
+func timeToTimestamp(t time.Time, e int64, nano bool) uint64 {
+	if nano {
+		return uint64(t.UnixNano()-e)&timestampMask | versionNano<<versionShift
+	}
+	return uint64(t.UnixMicro() - e/1e3)
+}
+
+// timestampToTime converts a timestamp counting from epoch e back to a time.
+func timestampToTime(ts uint64, e int64) time.Time {
+	if ts>>versionShift == versionNano {
+		return time.Unix(0, int64(ts&timestampMask)+e)
+	}
+	return time.UnixMicro(int64(ts) + e/1e3)
+}
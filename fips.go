@@ -0,0 +1,62 @@
+//go:build go1.24 && !tinygo
+
+package xtid
+
+import (
+	"bytes"
+	"crypto/fips140"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrFIPSUnavailable is returned by FIPSSource when the program does not run
+// in FIPS 140-3 mode.
+var ErrFIPSUnavailable = errors.New("xtid: FIPS 140-3 mode is not enabled (GODEBUG=fips140=on)")
+
+// FIPSSource returns a source backed by the SP 800-90A DRBG of the Go
+// Cryptographic Module. It requires the program to run in FIPS 140-3 mode,
+// and runs a self-test of the DRBG output before returning.
+func FIPSSource() (io.Reader, error) {
+	if !fips140.Enabled() {
+		return nil, ErrFIPSUnavailable
+	}
+	if err := selfTest(rand.Reader); err != nil {
+		return nil, err
+	}
+	return rand.Reader, nil
+}
+
+// WithFIPSSource makes the generator draw its payloads from FIPSSource,
+// failing if it is not available.
+func WithFIPSSource() Option {
+	return func(g *Generator) error {
+		src, err := FIPSSource()
+		if err != nil {
+			return err
+		}
+		g.source = src
+		return nil
+	}
+}
+
+// selfTest reads a few blocks from src and checks they are neither constant
+// nor repeated, in the spirit of the SP 800-90B continuous health tests.
+func selfTest(src io.Reader) error {
+	var blocks [4][16]byte
+	for i := range blocks {
+		if _, err := io.ReadFull(src, blocks[i][:]); err != nil {
+			return fmt.Errorf("xtid: entropy self-test: %w", err)
+		}
+		if bytes.Count(blocks[i][:], blocks[i][:1]) == len(blocks[i]) {
+			return errors.New("xtid: entropy self-test: constant output")
+		}
+		for j := 0; j < i; j++ {
+			if blocks[i] == blocks[j] {
+				return errors.New("xtid: entropy self-test: repeated output")
+			}
+		}
+	}
+	return nil
+}
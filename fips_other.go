@@ -0,0 +1,26 @@
+//go:build !go1.24 || tinygo
+
+package xtid
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrFIPSUnavailable is returned by FIPSSource when the program does not run
+// in FIPS 140-3 mode, which needs Go 1.24 and isn't supported by TinyGo.
+var ErrFIPSUnavailable = errors.New("xtid: FIPS 140-3 mode is not available with this toolchain")
+
+// FIPSSource always fails with ErrFIPSUnavailable before Go 1.24 and under
+// TinyGo.
+func FIPSSource() (io.Reader, error) {
+	return nil, ErrFIPSUnavailable
+}
+
+// WithFIPSSource always fails with ErrFIPSUnavailable before Go 1.24 and
+// under TinyGo.
+func WithFIPSSource() Option {
+	return func(g *Generator) error {
+		return ErrFIPSUnavailable
+	}
+}
@@ -0,0 +1,59 @@
+package xtid
+
+import (
+	"strings"
+)
+
+// XTIDList is a list of XTIDs satisfying the flag.Value interface, for
+// command line options given repeatedly or as comma-separated values:
+//
+//	var ids xtid.XTIDList
+//	flag.Var(&ids, "id", "IDs to process")
+//
+//	tool --id a --id b,c
+//
+// IDs given more than once are kept once, in the order they first appear.
+type XTIDList []XTID
+
+func (l XTIDList) String() string {
+	var b strings.Builder
+	for j, id := range l {
+		if j > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(id.String())
+	}
+	return b.String()
+}
+
+// Set appends the comma-separated IDs of s to the list.
+func (l *XTIDList) Set(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := Parse(part)
+		if err != nil {
+			return err
+		}
+		if !l.contains(id) {
+			*l = append(*l, id)
+		}
+	}
+	return nil
+}
+
+// Get satisfies the flag.Getter interface.
+func (l XTIDList) Get() any {
+	return []XTID(l)
+}
+
+func (l XTIDList) contains(id XTID) bool {
+	for _, x := range l {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
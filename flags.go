@@ -0,0 +1,121 @@
+package xtid
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// The largest number of flag bits a Registry accepts
+const maxFlagBits = 8
+
+// SetFlagBits makes the n low bits of the types flags rather than part of
+// the type numbers, standardizing markers such as tombstones:
+//
+//	xtid.DefaultRegistry.SetFlagBits(2)
+//	xtid.DefaultRegistry.RegisterFlag(1, "tombstone")
+//	dead := id.WithFlag(1)
+//
+// Registered types must have their flag bits clear, and names are looked up
+// ignoring them. It fails when n is larger than 8, when flags were already
+// set up differently, or when registered types have bits set among the n
+// low ones.
+func (r *Registry) SetFlagBits(n uint) error {
+	if n > maxFlagBits {
+		return fmt.Errorf("xtid: at most %d flag bits, got %d", maxFlagBits, n)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.flagBits == n {
+		return nil
+	}
+	if r.flagBits != 0 {
+		return fmt.Errorf("xtid: flag bits already set to %d", r.flagBits)
+	}
+	mask := uint16(1)<<n - 1
+	for typ, name := range r.types {
+		if typ&mask != 0 {
+			return fmt.Errorf("xtid: type %d (%s) overlaps the flag bits", typ, name)
+		}
+	}
+	r.flagBits = n
+	return nil
+}
+
+// FlagMask returns the mask of the flag bits of the types.
+func (r *Registry) FlagMask() uint16 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.flagMask()
+}
+
+// flagMask returns the mask of the flag bits. The caller must hold r.mu.
+func (r *Registry) flagMask() uint16 {
+	return uint16(1)<<r.flagBits - 1
+}
+
+// RegisterFlag names flag, a single bit among the flag bits.
+func (r *Registry) RegisterFlag(flag uint16, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if bits.OnesCount16(flag) != 1 || flag&r.flagMask() == 0 {
+		return fmt.Errorf("xtid: flag %#x is not a single flag bit", flag)
+	}
+	if old, ok := r.flags[flag]; ok {
+		return fmt.Errorf("xtid: flag %#x already registered as %q", flag, old)
+	}
+	r.flags[flag] = name
+	return nil
+}
+
+// FlagNames returns the names of the flags set in typ, lowest bit first.
+// Unregistered flags are named by their value.
+func (r *Registry) FlagNames(typ uint16) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var names []string
+	for f := typ & r.flagMask(); f != 0; f &= f - 1 {
+		flag := f & -f
+		if name, ok := r.flags[flag]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, fmt.Sprintf("%#x", flag))
+		}
+	}
+	return names
+}
+
+// BaseType returns the type of the ID without its flags, as set up in
+// DefaultRegistry.
+func (i XTID) BaseType() uint16 {
+	return i.Type() &^ DefaultRegistry.FlagMask()
+}
+
+// Flags returns the flag bits of the type of the ID, as set up in
+// DefaultRegistry.
+func (i XTID) Flags() uint16 {
+	return i.Type() & DefaultRegistry.FlagMask()
+}
+
+// HasFlag reports whether all the bits of flag are set in the type of the
+// ID, ignoring those which are not flag bits in DefaultRegistry. It is false
+// when none of them are.
+func (i XTID) HasFlag(flag uint16) bool {
+	flag &= DefaultRegistry.FlagMask()
+	return flag != 0 && i.Type()&flag == flag
+}
+
+// WithFlag returns the ID with the bits of flag set in its type, ignoring
+// those which are not flag bits in DefaultRegistry. The result is a
+// distinct ID.
+func (i XTID) WithFlag(flag uint16) XTID {
+	return i.WithType(i.Type() | flag&DefaultRegistry.FlagMask())
+}
+
+// WithoutFlag returns the ID with the bits of flag cleared in its type,
+// ignoring those which are not flag bits in DefaultRegistry.
+func (i XTID) WithoutFlag(flag uint16) XTID {
+	return i.WithType(i.Type() &^ (flag & DefaultRegistry.FlagMask()))
+}
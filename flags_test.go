@@ -0,0 +1,92 @@
+package xtid
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSetFlagBits(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(0x10, "user"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		n  uint
+		ok bool
+	}{
+		{9, false},
+		{5, false}, // overlaps type 0x10
+		{2, true},
+		{2, true},
+		{3, false},
+	} {
+		if err := r.SetFlagBits(tt.n); (err == nil) != tt.ok {
+			t.Errorf("SetFlagBits(%d) = %v", tt.n, err)
+		}
+	}
+	if m := r.FlagMask(); m != 0b11 {
+		t.Errorf("FlagMask = %#x, want 0x3", m)
+	}
+	if err := r.Register(0x21, "order"); err == nil {
+		t.Error("registered a type with flag bits set")
+	}
+
+	for _, tt := range []struct {
+		flag uint16
+		ok   bool
+	}{{1, true}, {2, true}, {1, false}, {3, false}, {4, false}, {0, false}} {
+		if err := r.RegisterFlag(tt.flag, "f"); (err == nil) != tt.ok {
+			t.Errorf("RegisterFlag(%#x) = %v", tt.flag, err)
+		}
+	}
+	if got, want := r.FlagNames(0x13), []string{"f", "f"}; !slices.Equal(got, want) {
+		t.Errorf("FlagNames = %q, want %q", got, want)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	if err := DefaultRegistry.SetFlagBits(2); err != nil {
+		t.Skip(err)
+	}
+	id := MakeWithPayload(Epoch(), 0x40, [10]byte{})
+
+	for _, tt := range []struct {
+		name  string
+		id    XTID
+		typ   uint16
+		flags uint16
+	}{
+		{"none", id, 0x40, 0},
+		{"with", id.WithFlag(1), 0x41, 1},
+		{"outside mask", id.WithFlag(0x100), 0x40, 0},
+		{"both", id.WithFlag(3), 0x43, 3},
+		{"without", id.WithFlag(3).WithoutFlag(1), 0x42, 2},
+		{"without outside mask", id.WithType(0x140).WithoutFlag(0x100), 0x140, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.id.Type() != tt.typ || tt.id.Flags() != tt.flags || tt.id.BaseType() != tt.typ&^3 {
+				t.Errorf("type %#x flags %#x base %#x, want %#x %#x", tt.id.Type(), tt.id.Flags(), tt.id.BaseType(), tt.typ, tt.flags)
+			}
+		})
+	}
+
+	flagged := id.WithType(0x143)
+	for _, tt := range []struct {
+		flag uint16
+		want bool
+	}{
+		{1, true},
+		{3, true},
+		{0, false},
+		// Bits outside the mask are type bits, not flags
+		{0x100, false},
+		{0x101, true},
+	} {
+		if got := flagged.HasFlag(tt.flag); got != tt.want {
+			t.Errorf("HasFlag(%#x) = %v, want %v", tt.flag, got, tt.want)
+		}
+	}
+	if id.HasFlag(1) {
+		t.Error("HasFlag(1) on an ID without flags")
+	}
+}
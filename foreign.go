@@ -0,0 +1,24 @@
+package xtid
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+)
+
+// ImportForeign derives a XTID for a record imported from another system,
+// from its creation time and its key in that system, foreign. The payload is
+// taken from a SHA-256 hash of typ and foreign, so importing the same record
+// again yields the same ID, while IDs keep the order in which the records
+// were created. The time must not be before the epoch.
+func ImportForeign(createdAt time.Time, typ uint16, foreign []byte) XTID {
+	h := sha256.New()
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], typ)
+	h.Write(b[:])
+	h.Write(foreign)
+
+	var payload [payloadLengthInBytes]byte
+	copy(payload[:], h.Sum(nil))
+	return MakeWithPayload(createdAt, typ, payload)
+}
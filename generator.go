@@ -0,0 +1,453 @@
+package xtid
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrCounterOverflow is returned by monotonic generators when the payload
+// counter wraps around within a single timestamp tick.
+var ErrCounterOverflow = errors.New("xtid: monotonic payload counter overflow")
+
+var errJitterOrder = errors.New("xtid: WithTimeJitter can't be combined with WithMonotonic or a clock policy")
+
+var errLeadingBytes = errors.New("xtid: leading payload bytes already reserved by WithNodeID or WithTenant")
+
+// A Generator mints XTIDs with a fixed set of options. Generators are safe
+// for concurrent use.
+type Generator struct {
+	// Source of random bytes, the package source when nil
+	source io.Reader
+
+	// Epoch in nanoseconds since the Unix epoch, the package epoch when nil
+	epoch *int64
+
+	// Leading payload bytes copied verbatim into every ID
+	prefix []byte
+	// Trailing payload bytes copied verbatim into every ID
+	suffix []byte
+
+	nano      bool
+	truncate  time.Duration
+	jitter    time.Duration
+	monotonic bool
+	clock     ClockPolicy
+	guard     *collisionGuard
+	maxFuture time.Duration
+
+	mu sync.Mutex
+	// Timestamp and payload of the last ID minted in monotonic mode
+	lastTs      uint64
+	lastPayload [payloadLengthInBytes]byte
+}
+
+// The generators behind the package-level constructors
+var (
+	defaultGenerator = &Generator{}
+	nanoGenerator    = &Generator{nano: true}
+)
+
+// DefaultGenerator returns the generator behind the package-level
+// constructors, such as New and Make.
+func DefaultGenerator() *Generator {
+	return defaultGenerator
+}
+
+// An Option configures a Generator.
+type Option func(*Generator) error
+
+// NewGenerator creates a Generator configured with opts.
+func NewGenerator(opts ...Option) (*Generator, error) {
+	g := &Generator{}
+	for _, opt := range opts {
+		if err := opt(g); err != nil {
+			return nil, err
+		}
+	}
+	if g.randomLength() < 1 {
+		return nil, fmt.Errorf("xtid: node ID, tenant tag and region take %d bytes, leaving no random payload bytes", len(g.prefix)+len(g.suffix))
+	}
+	if g.jitter > 0 && (g.monotonic || g.clock != ClockIgnore) {
+		return nil, errJitterOrder
+	}
+	return g, nil
+}
+
+// WithNodeID reserves the first size bytes of the payload for node, stored
+// big-endian, leaving the remaining bytes random. IDs minted by generators
+// with distinct node IDs can never collide. Use XTID.NodeID with the same
+// size to extract it.
+//
+// At least one payload byte must remain random: NewGenerator fails when the
+// reserved bytes take the whole payload.
+func WithNodeID(node uint64, size int) Option {
+	return func(g *Generator) error {
+		if len(g.prefix) != 0 {
+			return errLeadingBytes
+		}
+		if size < 1 || size > 8 {
+			return fmt.Errorf("xtid: node ID size must be between 1 and 8 bytes, got %d", size)
+		}
+		if size < 8 && node>>(8*size) != 0 {
+			return fmt.Errorf("xtid: node ID %d does not fit in %d bytes", node, size)
+		}
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], node)
+		g.prefix = append(g.prefix, b[8-size:]...)
+		return nil
+	}
+}
+
+// WithNanoseconds makes the generator mint IDs with nanosecond precision
+// timestamps, see MakeNano.
+func WithNanoseconds() Option {
+	return func(g *Generator) error {
+		g.nano = true
+		return nil
+	}
+}
+
+// WithSource makes the generator read its random bytes from src instead of
+// the package source, so that libraries and tests can use their own source
+// without touching the global one set by SetSource. src must be safe for
+// concurrent use if the generator is.
+func WithSource(src io.Reader) Option {
+	return func(g *Generator) error {
+		if src == nil {
+			return fmt.Errorf("xtid: nil source")
+		}
+		g.source = src
+		return nil
+	}
+}
+
+// WithMonotonic makes the generator seed the random part of the payload once
+// per timestamp tick and increment it for every further ID minted within the
+// same tick, ULID-style. IDs from a single monotonic generator are strictly
+// increasing for a non-decreasing clock, and minting them costs a single
+// entropy read per tick instead of one per ID.
+func WithMonotonic() Option {
+	return func(g *Generator) error {
+		g.monotonic = true
+		return nil
+	}
+}
+
+// A ClockPolicy tells a Generator what to do when the clock moves backwards.
+type ClockPolicy int
+
+const (
+	// ClockIgnore mints IDs with whatever time it is given. This is the
+	// default.
+	ClockIgnore ClockPolicy = iota
+	// ClockHold keeps using the last timestamp until the clock catches up,
+	// incrementing the payload of the last ID like WithMonotonic does.
+	ClockHold
+	// ClockError fails with a *ClockRegressionError.
+	ClockError
+)
+
+// ClockRegressionError is returned by generators using ClockError when asked
+// to mint an ID older than the last one they minted.
+type ClockRegressionError struct {
+	Last time.Time
+	Now  time.Time
+}
+
+func (e *ClockRegressionError) Error() string {
+	return fmt.Sprintf("xtid: clock moved backwards by %v", e.Last.Sub(e.Now))
+}
+
+// WithClockPolicy sets how the generator reacts to the clock moving
+// backwards, as happens on NTP steps or VM migrations. The check applies to
+// every ID the generator mints, so generators backfilling historical data
+// through Make should keep the default ClockIgnore.
+//
+// Combined with WithMonotonic, ClockHold keeps the IDs of a generator
+// strictly increasing across clock regressions.
+func WithClockPolicy(p ClockPolicy) Option {
+	return func(g *Generator) error {
+		if p < ClockIgnore || p > ClockError {
+			return fmt.Errorf("xtid: invalid clock policy %d", p)
+		}
+		g.clock = p
+		return nil
+	}
+}
+
+// NewWithType makes a new XTID of type typ stamped with the current time.
+func (g *Generator) NewWithType(typ uint16) (XTID, error) {
+	return g.Make(time.Now(), typ)
+}
+
+// Make a new XTID using custom time and type
+func (g *Generator) Make(t time.Time, typ uint16) (id XTID, err error) {
+	if id, err = g.mint(t, typ); err == nil {
+		notifyGenerate(id)
+	}
+	return
+}
+
+// MakeWithTrailer is like Make, storing trailer in the last bytes of the
+// payload of the ID in place of random bytes, for packages embedding data in
+// their IDs, such as xtidttl. Unlike bytes patched into a minted ID, the
+// trailer keeps the leading bytes reserved by WithNodeID or WithTenant, is
+// part of the ID checked by the collision guard and passed to the OnGenerate
+// hooks, and is kept by WithMonotonic, which increments the bytes before
+// it.
+//
+// It fails if the generator stores a region in the last byte, see
+// WithRegion, or if the trailer leaves no random payload byte.
+func (g *Generator) MakeWithTrailer(t time.Time, typ uint16, trailer []byte) (id XTID, err error) {
+	if len(g.suffix) != 0 {
+		return Nil, fmt.Errorf("xtid: trailer conflicts with the region of the generator")
+	}
+	if len(g.prefix)+len(trailer) >= payloadLengthInBytes {
+		return Nil, fmt.Errorf("xtid: trailer of %d bytes leaves no random payload bytes", len(trailer))
+	}
+	if id, err = g.mintWith(t, typ, trailer); err == nil {
+		notifyGenerate(id)
+	}
+	return
+}
+
+// mint is Make without calling the OnGenerate hooks.
+func (g *Generator) mint(t time.Time, typ uint16) (XTID, error) {
+	return g.mintWith(t, typ, g.suffix)
+}
+
+// mintWith mints an ID whose payload ends with suffix.
+func (g *Generator) mintWith(t time.Time, typ uint16, suffix []byte) (id XTID, err error) {
+	for attempt := 0; ; attempt++ {
+		if id, err = g.make(t, typ, suffix); err != nil {
+			return
+		}
+		if g.guard == nil || g.guard.add(&id) {
+			return
+		}
+		if attempt == maxCollisionRetries {
+			return Nil, ErrCollision
+		}
+	}
+}
+
+func (g *Generator) make(t time.Time, typ uint16, suffix []byte) (id XTID, err error) {
+	ts, err := g.timestamp(t)
+	if err != nil {
+		return
+	}
+
+	if g.monotonic || g.clock != ClockIgnore {
+		ts, err = g.nextPayload(ts, typ, id[payloadStart:], suffix)
+	} else {
+		err = g.fillPayload(typ, id[payloadStart:], suffix)
+	}
+
+	if err != nil {
+		id = Nil // don't leak random bytes on error
+		return
+	}
+
+	binary.BigEndian.PutUint64(id[:timestampLengthInBytes], ts)
+	binary.BigEndian.PutUint16(id[timestampLengthInBytes:payloadStart], typ)
+
+	return
+}
+
+// timestamp returns the timestamp of an ID minted at t.
+func (g *Generator) timestamp(t time.Time) (uint64, error) {
+	t = g.blur(t)
+	if err := g.checkTime(t); err != nil {
+		return 0, err
+	}
+	return timeToTimestamp(t, g.epochNanos(), g.nano), nil
+}
+
+// NewBatch makes n new XTIDs of type typ stamped with the current time. The
+// random bytes of all the IDs are read from the source at once, which is much
+// cheaper than one read per ID. Monotonic generators and those checking the
+// clock mint the IDs one by one, like NewWithType.
+func (g *Generator) NewBatch(typ uint16, n int) ([]XTID, error) {
+	ids := make([]XTID, n)
+	t := time.Now()
+	if !g.batchable() {
+		for j := range ids {
+			id, err := g.Make(t, typ)
+			if err != nil {
+				return nil, err
+			}
+			ids[j] = id
+		}
+		return ids, nil
+	}
+
+	buf := make([]byte, n*g.randomLength())
+	defer clear(buf)
+	if err := g.readRandom(typ, buf); err != nil {
+		return nil, err
+	}
+	for j := range ids {
+		id, err := g.makeFrom(t, typ, buf[j*g.randomLength():])
+		if err != nil {
+			return nil, err
+		}
+		ids[j] = id
+	}
+	return ids, nil
+}
+
+// batchable reports whether the IDs of the generator can be minted from
+// random bytes read ahead, that is whether they don't depend on the last ID.
+func (g *Generator) batchable() bool {
+	return !g.monotonic && g.clock == ClockIgnore
+}
+
+// randomLength returns the number of random bytes in the payload of an ID.
+func (g *Generator) randomLength() int {
+	return payloadLengthInBytes - len(g.prefix) - len(g.suffix)
+}
+
+// sourceFor returns the source of the random bytes of the IDs of type typ:
+// the source of the generator, or else the one set for typ by
+// SetSourceForType, or else the package source.
+func (g *Generator) sourceFor(typ uint16) io.Reader {
+	if g.source != nil {
+		return g.source
+	}
+	return loadSourceForType(typ)
+}
+
+// readRandom fills p with random bytes read from the source of type typ.
+func (g *Generator) readRandom(typ uint16, p []byte) error {
+	if _, err := io.ReadFull(g.sourceFor(typ), p); err != nil {
+		return &EntropyError{Err: err}
+	}
+	return nil
+}
+
+// makeFrom mints an ID taking its random bytes from the start of rnd, for
+// batchable generators only.
+func (g *Generator) makeFrom(t time.Time, typ uint16, rnd []byte) (id XTID, err error) {
+	ts, err := g.timestamp(t)
+	if err != nil {
+		return
+	}
+	n := copy(id[payloadStart:], g.prefix)
+	copy(id[payloadStart+n:], rnd[:g.randomLength()])
+	copy(id[byteLength-len(g.suffix):], g.suffix)
+	binary.BigEndian.PutUint64(id[:timestampLengthInBytes], ts)
+	binary.BigEndian.PutUint16(id[timestampLengthInBytes:payloadStart], typ)
+
+	if g.guard != nil && !g.guard.add(&id) {
+		return g.Make(t, typ)
+	}
+	notifyGenerate(id)
+	return
+}
+
+func (g *Generator) epochNanos() int64 {
+	if g.epoch != nil {
+		return *g.epoch
+	}
+	return epoch
+}
+
+// fillPayload writes the prefix followed by random bytes and suffix into p,
+// taking the random bytes from the source of type typ.
+func (g *Generator) fillPayload(typ uint16, p, suffix []byte) error {
+	src := g.sourceFor(typ)
+	if ps, ok := src.(PayloadSource); ok {
+		// The prefix and suffix overwrite the first and last random bytes
+		if err := ps.FillPayload((*[payloadLengthInBytes]byte)(p)); err != nil {
+			clear(p)
+			return &EntropyError{Err: err}
+		}
+		copy(p, g.prefix)
+		copy(p[len(p)-len(suffix):], suffix)
+		return nil
+	}
+	n := copy(p, g.prefix)
+	if _, err := io.ReadFull(src, p[n:len(p)-len(suffix)]); err != nil {
+		return &EntropyError{Err: err}
+	}
+	copy(p[len(p)-len(suffix):], suffix)
+	return nil
+}
+
+// nextPayload writes the payload of the ID minted at ts into p, taking the
+// last minted ID into account, and returns the timestamp to use for it.
+//
+// In monotonic mode the payload follows the last one when ts did not change,
+// and a clock regression under ClockHold is treated the same way: the bytes
+// between the prefix and suffix are incremented, so that the payload stays
+// greater even when suffix differs from the last one.
+func (g *Generator) nextPayload(ts uint64, typ uint16, p, suffix []byte) (uint64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ts, hold, err := g.checkClock(ts)
+	if err != nil {
+		return 0, err
+	}
+
+	if ts == g.lastTs && (g.monotonic || hold) {
+		if !increment(g.lastPayload[len(g.prefix) : payloadLengthInBytes-len(suffix)]) {
+			return 0, ErrCounterOverflow
+		}
+		copy(g.lastPayload[payloadLengthInBytes-len(suffix):], suffix)
+	} else if err := g.fillPayload(typ, g.lastPayload[:], suffix); err != nil {
+		return 0, err
+	}
+	g.lastTs = ts
+
+	copy(p, g.lastPayload[:])
+	return ts, nil
+}
+
+// checkClock applies the clock policy of g to the timestamp ts, reporting
+// whether it holds the clock at the last timestamp. g.mu must be held.
+func (g *Generator) checkClock(ts uint64) (uint64, bool, error) {
+	if ts >= g.lastTs {
+		return ts, false, nil
+	}
+	switch g.clock {
+	case ClockHold:
+		return g.lastTs, true, nil
+	case ClockError:
+		return 0, false, &ClockRegressionError{
+			Last: timestampToTime(g.lastTs, g.epochNanos()),
+			Now:  timestampToTime(ts, g.epochNanos()),
+		}
+	}
+	return ts, false, nil
+}
+
+// increment adds one to the big-endian integer in b. It reports false and
+// leaves b untouched if that would wrap around.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] != 0xff {
+			b[i]++
+			for j := i + 1; j < len(b); j++ {
+				b[j] = 0
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// NodeID returns the node ID stored in the first size bytes of the payload
+// by a Generator configured with WithNodeID.
+func (i XTID) NodeID(size int) uint64 {
+	if size < 1 || size > 8 {
+		return 0
+	}
+	var b [8]byte
+	copy(b[8-size:], i[payloadStart:payloadStart+size])
+	return binary.BigEndian.Uint64(b[:])
+}
@@ -0,0 +1,136 @@
+package xtid
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewGeneratorOptions(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []Option
+		ok   bool
+	}{
+		{"node ID", []Option{WithNodeID(1, 8)}, true},
+		{"node ID and region", []Option{WithNodeID(1, 8), WithRegion(3)}, true},
+		{"node ID twice", []Option{WithNodeID(1, 2), WithNodeID(2, 2)}, false},
+		{"node ID and tenant", []Option{WithNodeID(1, 2), WithTenant("a", 2)}, false},
+		{"node ID too large", []Option{WithNodeID(256, 1)}, false},
+		{"jitter", []Option{WithTimeJitter(time.Second)}, true},
+		{"jitter and truncation", []Option{WithTimeJitter(time.Second), WithTimeTruncation(time.Minute)}, true},
+		{"jitter and monotonic", []Option{WithTimeJitter(time.Second), WithMonotonic()}, false},
+		{"jitter and clock hold", []Option{WithClockPolicy(ClockHold), WithTimeJitter(time.Second)}, false},
+		{"jitter and clock error", []Option{WithClockPolicy(ClockError), WithTimeJitter(time.Second)}, false},
+		{"truncation and monotonic", []Option{WithTimeTruncation(time.Minute), WithMonotonic()}, true},
+		{"invalid clock policy", []Option{WithClockPolicy(ClockError + 1)}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGenerator(tt.opts...); (err == nil) != tt.ok {
+				t.Errorf("NewGenerator = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGeneratorClock(t *testing.T) {
+	t0 := time.Now()
+	earlier := t0.Add(-time.Second)
+
+	for _, tt := range []struct {
+		name string
+		opts []Option
+		// The times of the IDs, minted in order
+		times []time.Time
+		// Whether the IDs must be strictly increasing
+		increasing bool
+		err        error
+	}{
+		{"monotonic", []Option{WithMonotonic()}, []time.Time{t0, t0, t0, t0}, true, nil},
+		{"monotonic regression", []Option{WithMonotonic()}, []time.Time{t0, earlier}, false, nil},
+		{"monotonic hold", []Option{WithMonotonic(), WithClockPolicy(ClockHold)}, []time.Time{t0, t0, earlier, earlier, t0}, true, nil},
+		{"hold", []Option{WithClockPolicy(ClockHold)}, []time.Time{t0, earlier, earlier}, true, nil},
+		{"error", []Option{WithClockPolicy(ClockError)}, []time.Time{t0, t0, earlier}, false, &ClockRegressionError{}},
+		{"monotonic node ID", []Option{WithMonotonic(), WithNodeID(7, 2)}, []time.Time{t0, t0, t0}, true, nil},
+		{"monotonic region", []Option{WithMonotonic(), WithRegion(9)}, []time.Time{t0, t0, t0}, true, nil},
+		{"truncation", []Option{WithMonotonic(), WithTimeTruncation(time.Hour)}, []time.Time{t0, t0.Add(time.Millisecond)}, true, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGenerator(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var prev XTID
+			for j, at := range tt.times {
+				id, err := g.Make(at, 1)
+				if err != nil {
+					var cre *ClockRegressionError
+					if tt.err == nil || !errors.As(err, &cre) || j != len(tt.times)-1 {
+						t.Fatalf("Make #%d: %v", j, err)
+					}
+					if !cre.Last.Equal(t0.Truncate(time.Microsecond)) || !cre.Now.Equal(earlier.Truncate(time.Microsecond)) {
+						t.Errorf("ClockRegressionError %v -> %v, want %v -> %v", cre.Last, cre.Now, t0, earlier)
+					}
+					return
+				}
+				if j > 0 && tt.increasing && Compare(id, prev) <= 0 {
+					t.Errorf("ID #%d %s not after %s", j, id, prev)
+				}
+				prev = id
+			}
+			if tt.err != nil {
+				t.Errorf("no error, want %T", tt.err)
+			}
+		})
+	}
+}
+
+func TestGeneratorMonotonicPayload(t *testing.T) {
+	g, err := NewGenerator(WithMonotonic(), WithNodeID(0xbeef, 2), WithRegion(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Now()
+	a, err := g.Make(t0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := g.Make(t0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Timestamp() != b.Timestamp() {
+		t.Errorf("timestamps %d and %d differ within a tick", a.Timestamp(), b.Timestamp())
+	}
+	pa, pb := a.Payload(), b.Payload()
+	for _, p := range [][10]byte{pa, pb} {
+		if p[0] != 0xbe || p[1] != 0xef || p[9] != 5 {
+			t.Errorf("payload %x lost the node ID or region", p)
+		}
+	}
+	if bytes.Compare(pb[2:9], pa[2:9]) <= 0 {
+		t.Errorf("payload %x not after %x", pb, pa)
+	}
+}
+
+func TestGeneratorCounterOverflow(t *testing.T) {
+	// A single random byte overflows within 256 IDs
+	g, err := NewGenerator(WithMonotonic(), WithNodeID(1, 8), WithRegion(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Now()
+	for range 257 {
+		if _, err = g.Make(t0, 1); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, ErrCounterOverflow) {
+		t.Errorf("Make = %v, want %v", err, ErrCounterOverflow)
+	}
+	// The next tick starts over
+	if _, err := g.Make(t0.Add(time.Millisecond), 1); err != nil {
+		t.Errorf("Make on the next tick: %v", err)
+	}
+}
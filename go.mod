@@ -1,3 +1,3 @@
 module github.com/it512/xtid
 
-go 1.20
+go 1.23
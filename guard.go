@@ -0,0 +1,86 @@
+package xtid
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Number of times a Generator regenerates an ID colliding with a recent one
+// before giving up with ErrCollision.
+const maxCollisionRetries = 8
+
+// ErrCollision is returned by generators using WithCollisionGuard when they
+// keep minting IDs that collide with recent ones, which indicates a broken
+// entropy source.
+var ErrCollision = errors.New("xtid: repeated collision with recently minted IDs")
+
+// The timestamp and payload of an ID
+type guardKey [timestampLengthInBytes + payloadLengthInBytes]byte
+
+// collisionGuard remembers the last minted IDs in a ring buffer.
+type collisionGuard struct {
+	mu   sync.Mutex
+	ring []guardKey
+	next int
+	seen map[guardKey]struct{}
+
+	collisions atomic.Uint64
+}
+
+func newCollisionGuard(size int) *collisionGuard {
+	return &collisionGuard{
+		ring: make([]guardKey, 0, size),
+		seen: make(map[guardKey]struct{}, size),
+	}
+}
+
+// add records id, or reports false if an ID with the same timestamp and
+// payload is still in the window.
+func (c *collisionGuard) add(id *XTID) bool {
+	var k guardKey
+	copy(k[:], id[:timestampLengthInBytes])
+	copy(k[timestampLengthInBytes:], id[payloadStart:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.seen[k]; ok {
+		c.collisions.Add(1)
+		return false
+	}
+
+	if len(c.ring) < cap(c.ring) {
+		c.ring = append(c.ring, k)
+	} else {
+		delete(c.seen, c.ring[c.next])
+		c.ring[c.next] = k
+		c.next = (c.next + 1) % len(c.ring)
+	}
+	c.seen[k] = struct{}{}
+	return true
+}
+
+// WithCollisionGuard makes the generator remember the last size IDs it
+// minted and regenerate any new ID whose timestamp and payload match one of
+// them. This is only useful with weakened entropy sources; Collisions
+// reports how often it happened.
+func WithCollisionGuard(size int) Option {
+	return func(g *Generator) error {
+		if size < 1 {
+			return fmt.Errorf("xtid: collision guard size must be positive, got %d", size)
+		}
+		g.guard = newCollisionGuard(size)
+		return nil
+	}
+}
+
+// Collisions returns the number of collisions detected by the collision
+// guard of g, see WithCollisionGuard.
+func (g *Generator) Collisions() uint64 {
+	if g.guard == nil {
+		return 0
+	}
+	return g.guard.collisions.Load()
+}
@@ -0,0 +1,65 @@
+package xtid
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// The hooks installed by OnGenerate and OnParseError, replaced as a
+	// whole on every change so that they run without locking
+	generateHooks   atomic.Pointer[[]func(XTID)]
+	parseErrorHooks atomic.Pointer[[]func(string, error)]
+	hooksMu         sync.Mutex
+)
+
+// OnGenerate installs hook, called with every ID minted by the generators,
+// those behind New and Make included, in batches and streams too, so that
+// audit trails, sampling tracers or metrics see every ID without wrapping
+// the constructors at call sites. IDs built from given bytes, such as those
+// of MakeWithPayload, Derive or FromContent, are not reported.
+//
+// Hooks run synchronously, in the order they were installed, on the
+// goroutine minting the ID: they must be fast and safe for concurrent use.
+// They are meant to be installed at startup, and cannot be removed.
+func OnGenerate(hook func(XTID)) {
+	addHook(&generateHooks, hook)
+}
+
+// OnParseError installs hook, called with the input and the error of every
+// string form that fails to decode: in Parse, and thus UnmarshalText,
+// UnmarshalJSON and Scan, as well as in ParseBatch and Decoder.Decode. The
+// same rules as for OnGenerate apply.
+func OnParseError(hook func(input string, err error)) {
+	addHook(&parseErrorHooks, hook)
+}
+
+func addHook[F any](hooks *atomic.Pointer[[]F], hook F) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	var fs []F
+	if old := hooks.Load(); old != nil {
+		fs = append(fs, *old...)
+	}
+	fs = append(fs, hook)
+	hooks.Store(&fs)
+}
+
+// notifyGenerate calls the hooks installed by OnGenerate with id.
+func notifyGenerate(id XTID) {
+	if fs := generateHooks.Load(); fs != nil {
+		for _, f := range *fs {
+			f(id)
+		}
+	}
+}
+
+// notifyParseError calls the hooks installed by OnParseError.
+func notifyParseError(s string, err error) {
+	if fs := parseErrorHooks.Load(); fs != nil {
+		for _, f := range *fs {
+			f(s, err)
+		}
+	}
+}
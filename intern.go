@@ -0,0 +1,62 @@
+package xtid
+
+import (
+	"sync"
+)
+
+// The number of IDs kept by the Interner used by Intern
+const defaultInternSize = 1 << 16
+
+// Interner hands out a single string for identical IDs rendered repeatedly,
+// sparing an allocation each time. It keeps a bounded number of recently
+// used IDs, in two generations: when the current one is full, it replaces
+// the previous one, and IDs found in the previous one are carried over.
+// It is safe for concurrent use.
+type Interner struct {
+	mu   sync.RWMutex
+	size int
+	cur  map[XTID]string
+	prev map[XTID]string
+}
+
+// NewInterner returns an Interner keeping between size and twice size IDs.
+func NewInterner(size int) *Interner {
+	if size < 1 {
+		size = 1
+	}
+	return &Interner{size: size, cur: make(map[XTID]string, size)}
+}
+
+// Intern returns the string representation of id, shared with the previous
+// calls for id while it is kept.
+func (in *Interner) Intern(id XTID) string {
+	in.mu.RLock()
+	s, ok := in.cur[id]
+	in.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if s, ok := in.cur[id]; ok {
+		return s
+	}
+	s, ok = in.prev[id]
+	if !ok {
+		s = id.String()
+	}
+	if len(in.cur) >= in.size {
+		in.prev, in.cur = in.cur, make(map[XTID]string, in.size)
+	}
+	in.cur[id] = s
+	return s
+}
+
+var defaultInterner = NewInterner(defaultInternSize)
+
+// Intern returns the string representation of id, shared process-wide with
+// the previous calls for id while it is among the most recently interned.
+func Intern(id XTID) string {
+	return defaultInterner.Intern(id)
+}
@@ -0,0 +1,51 @@
+package xtid
+
+import (
+	"strings"
+)
+
+// The prefix ParseLenient always strips
+const lenientPrefix = "xtid:"
+
+// ParseLenient decodes a XTID copied from logs, chat messages or
+// spreadsheets: it strips surrounding whitespace and quotes, the "xtid:"
+// prefix and any of prefixes, matched case-insensitively, and when given a
+// URL or path, keeps its last segment. What remains is decoded by Parse.
+//
+//	xtid.ParseLenient(` "xtid:0ujtsYcgvSTl8PAuAdqWYSMnLOv" `)
+//	xtid.ParseLenient("https://example.com/orders/0ujtsYcgvSTl8PAuAdqWYSMnLOv?tab=1")
+func ParseLenient(s string, prefixes ...string) (XTID, error) {
+	s = trimQuotes(strings.TrimSpace(s))
+
+	if strings.ContainsAny(s, "/?#") {
+		if i := strings.IndexAny(s, "?#"); i >= 0 {
+			s = s[:i]
+		}
+		s = strings.TrimRight(s, "/")
+		s = s[strings.LastIndexByte(s, '/')+1:]
+	}
+
+	s = trimPrefixFold(s, lenientPrefix)
+	for _, p := range prefixes {
+		s = trimPrefixFold(s, p)
+	}
+	return Parse(trimQuotes(strings.TrimSpace(s)))
+}
+
+func trimQuotes(s string) string {
+	for len(s) >= 2 {
+		first, last := s[0], s[len(s)-1]
+		if first != last || (first != '"' && first != '\'' && first != '`') {
+			break
+		}
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	return s
+}
+
+func trimPrefixFold(s, prefix string) string {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):]
+	}
+	return s
+}
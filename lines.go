@@ -0,0 +1,134 @@
+package xtid
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// LineError reports an invalid line read by a LineReader.
+type LineError struct {
+	// Line is the 1-based number of the line
+	Line int64
+	Text string
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("xtid: line %d: %q: %v", e.Line, e.Text, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineWriter writes XTIDs as newline-delimited base62 strings, or as an
+// NDJSON stream of JSON strings when Quoted is set.
+type LineWriter struct {
+	// Quoted writes each ID as a JSON string.
+	Quoted bool
+
+	w *bufio.Writer
+	n int64
+}
+
+// NewLineWriter returns a LineWriter writing to w. Flush must be called once
+// done.
+func NewLineWriter(w io.Writer) *LineWriter {
+	return &LineWriter{w: bufio.NewWriter(w)}
+}
+
+// Write writes id on a line of its own.
+func (l *LineWriter) Write(id XTID) error {
+	var buf [stringEncodedLength + 3]byte
+	b := buf[:0]
+	if l.Quoted {
+		b = append(b, '"')
+	}
+	b = id.Append(b)
+	if l.Quoted {
+		b = append(b, '"')
+	}
+	b = append(b, '\n')
+	if _, err := l.w.Write(b); err != nil {
+		return err
+	}
+	l.n++
+	return nil
+}
+
+// Count returns the number of IDs written.
+func (l *LineWriter) Count() int64 {
+	return l.n
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (l *LineWriter) Flush() error {
+	return l.w.Flush()
+}
+
+// LineReader reads XTIDs written one per line, as base62 strings or JSON
+// strings. Leading and trailing whitespace is ignored, as are empty lines.
+type LineReader struct {
+	s        *bufio.Scanner
+	line     int64
+	ids      int64
+	every    int64
+	progress func(lines, ids int64)
+}
+
+// NewLineReader returns a LineReader reading from r.
+func NewLineReader(r io.Reader) *LineReader {
+	return &LineReader{s: bufio.NewScanner(r)}
+}
+
+// SetProgress makes the reader call fn with the number of lines and IDs read
+// so far every n lines, and once at the end of the input.
+func (l *LineReader) SetProgress(n int64, fn func(lines, ids int64)) {
+	l.every, l.progress = n, fn
+}
+
+// Next returns the ID of the next line, or io.EOF at the end of the input.
+// Invalid lines are reported as *LineError, and reading can go on past them.
+func (l *LineReader) Next() (XTID, error) {
+	for l.s.Scan() {
+		l.line++
+		text := bytes.TrimSpace(l.s.Bytes())
+		if len(text) == 0 {
+			l.report()
+			continue
+		}
+		s := text
+		if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+			s = s[1 : len(s)-1]
+		}
+		id, err := Parse(string(s))
+		if err != nil {
+			l.report()
+			return Nil, &LineError{Line: l.line, Text: string(text), Err: err}
+		}
+		l.ids++
+		l.report()
+		return id, nil
+	}
+	if err := l.s.Err(); err != nil {
+		return Nil, err
+	}
+	if l.progress != nil {
+		l.progress(l.line, l.ids)
+		l.progress = nil
+	}
+	return Nil, io.EOF
+}
+
+func (l *LineReader) report() {
+	if l.progress != nil && l.every > 0 && l.line%l.every == 0 {
+		l.progress(l.line, l.ids)
+	}
+}
+
+// Line returns the number of the last line read.
+func (l *LineReader) Line() int64 {
+	return l.line
+}
@@ -0,0 +1,74 @@
+package xtid
+
+import (
+	"fmt"
+	"time"
+)
+
+// NK combines a namespace and a kind into a type, the namespace being the
+// high byte. Splitting the type space this way lets every team of a large
+// organization allocate kinds within its own namespace.
+func NK(ns, kind uint8) uint16 {
+	return uint16(ns)<<8 | uint16(kind)
+}
+
+// MakeNK makes a new XTID using custom time and the type NK(ns, kind).
+func MakeNK(t time.Time, ns, kind uint8) (XTID, error) {
+	return Make(t, NK(ns, kind))
+}
+
+// Namespace returns the namespace of the type of the ID, see NK.
+func (i XTID) Namespace() uint8 {
+	return uint8(i.Type() >> 8)
+}
+
+// Kind returns the kind of the type of the ID, see NK.
+func (i XTID) Kind() uint8 {
+	return uint8(i.Type())
+}
+
+// RegisterNamespace names namespace ns. It fails if ns or name are already
+// registered.
+func (r *Registry) RegisterNamespace(ns uint8, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if old, ok := r.namespaces[ns]; ok {
+		return fmt.Errorf("xtid: namespace %d already registered as %q", ns, old)
+	}
+	for n, old := range r.namespaces {
+		if old == name {
+			return fmt.Errorf("xtid: namespace name %q already registered for namespace %d", name, n)
+		}
+	}
+	r.namespaces[ns] = name
+	return nil
+}
+
+// NamespaceName returns the name registered for namespace ns.
+func (r *Registry) NamespaceName(ns uint8) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.namespaces[ns]
+	return name, ok
+}
+
+// RegisterKind names the type NK(ns, kind) "<namespace>.<name>". The
+// namespace must have been registered first.
+func (r *Registry) RegisterKind(ns, kind uint8, name string) error {
+	nsName, ok := r.NamespaceName(ns)
+	if !ok {
+		return fmt.Errorf("xtid: namespace %d is not registered", ns)
+	}
+	return r.Register(NK(ns, kind), nsName+"."+name)
+}
+
+// RegisterNamespace names namespace ns in DefaultRegistry.
+func RegisterNamespace(ns uint8, name string) error {
+	return DefaultRegistry.RegisterNamespace(ns, name)
+}
+
+// RegisterKind names the type NK(ns, kind) in DefaultRegistry.
+func RegisterKind(ns, kind uint8, name string) error {
+	return DefaultRegistry.RegisterKind(ns, kind, name)
+}
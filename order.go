@@ -0,0 +1,78 @@
+package xtid
+
+import (
+	"fmt"
+)
+
+// Less reports whether a sorts before b. It is the less function expected
+// by generic ordered containers, such as google/btree and tidwall/btree:
+//
+//	tree := btree.NewG(32, xtid.Less)
+//
+// The Item interface of the older, non-generic google/btree API names a type
+// of that package in its Less method, which no type of this package can
+// implement without depending on it. Wrap IDs in a type of your own there:
+//
+//	type item xtid.XTID
+//
+//	func (i item) Less(than btree.Item) bool { return xtid.Less(xtid.XTID(i), xtid.XTID(than.(item))) }
+func Less(a, b XTID) bool {
+	return Compare(a, b) < 0
+}
+
+// LessBy returns a less function ordering values of type T by the XTID key
+// returns for them, for containers of entries keyed by ID:
+//
+//	tree := btree.NewG(32, xtid.LessBy(func(s *Session) xtid.XTID { return s.ID }))
+func LessBy[T any](key func(T) XTID) func(a, b T) bool {
+	return func(a, b T) bool {
+		return Compare(key(a), key(b)) < 0
+	}
+}
+
+// CompareBy is like LessBy, for containers expecting a three-way comparison.
+func CompareBy[T any](key func(T) XTID) func(a, b T) int {
+	return func(a, b T) int {
+		return Compare(key(a), key(b))
+	}
+}
+
+// An Entry pairs a value with the XTID it is keyed by, for ordered
+// containers of values which don't carry their ID.
+type Entry[V any] struct {
+	ID    XTID
+	Value V
+}
+
+// LessEntries orders entries by ID.
+func LessEntries[V any](a, b Entry[V]) bool {
+	return Compare(a.ID, b.ID) < 0
+}
+
+// Comparator compares XTIDs, and pointers to them, held in empty
+// interfaces. It satisfies the Comparable interface of huandu/skiplist:
+//
+//	list := skiplist.New(xtid.Comparator{})
+type Comparator struct{}
+
+// Compare orders lhs and rhs, panicking when they are not XTIDs.
+func (Comparator) Compare(lhs, rhs any) int {
+	return Compare(asXTID(lhs), asXTID(rhs))
+}
+
+// CalcScore returns a score growing with the timestamp of key, which
+// skiplists use to skip comparisons.
+func (Comparator) CalcScore(key any) float64 {
+	return float64(asXTID(key).rawTimestamp())
+}
+
+func asXTID(v any) XTID {
+	switch v := v.(type) {
+	case XTID:
+		return v
+	case *XTID:
+		return *v
+	default:
+		panic(fmt.Sprintf("xtid: can't compare %T to a XTID", v))
+	}
+}
@@ -0,0 +1,192 @@
+package xtid
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// The number of IDs buffered by a PackedWriter before writing a block
+const packedBlockLength = 4096
+
+var errPackedClosed = errors.New("xtid: write to closed PackedWriter")
+
+// PackedWriter writes XTIDs as a stream of packed 20-byte records, for
+// exporting large sets of IDs without going through their text encoding.
+//
+// The stream is made of blocks, each a uvarint count of records followed by
+// the records themselves, and ends with an empty block. Read it back with a
+// PackedReader.
+type PackedWriter struct {
+	w   io.Writer
+	buf []byte
+	n   int
+	err error
+}
+
+// NewPackedWriter returns a PackedWriter writing to w. Close must be called
+// to terminate the stream.
+func NewPackedWriter(w io.Writer) *PackedWriter {
+	return &PackedWriter{
+		w:   w,
+		buf: make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+packedBlockLength*byteLength),
+	}
+}
+
+// Write appends id to the stream.
+func (p *PackedWriter) Write(id XTID) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.buf = append(p.buf, id[:]...)
+	p.n++
+	if p.n == packedBlockLength {
+		return p.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered IDs to the underlying writer.
+func (p *PackedWriter) Flush() error {
+	if p.err != nil || p.n == 0 {
+		return p.err
+	}
+	// The count is written right before the records, in the space reserved
+	// at the start of the buffer.
+	var count [binary.MaxVarintLen64]byte
+	l := binary.PutUvarint(count[:], uint64(p.n))
+	start := binary.MaxVarintLen64 - l
+	copy(p.buf[start:], count[:l])
+
+	if _, err := p.w.Write(p.buf[start:]); err != nil {
+		p.err = err
+		return err
+	}
+	p.buf = p.buf[:binary.MaxVarintLen64]
+	p.n = 0
+	return nil
+}
+
+// Close flushes the buffered IDs and terminates the stream. It does not close
+// the underlying writer.
+func (p *PackedWriter) Close() error {
+	if err := p.Flush(); err != nil {
+		return err
+	}
+	if _, err := p.w.Write([]byte{0}); err != nil {
+		p.err = err
+		return err
+	}
+	p.err = errPackedClosed
+	return nil
+}
+
+// PackedReader reads XTIDs from a stream written by a PackedWriter.
+type PackedReader struct {
+	r    *bufio.Reader
+	left uint64
+	err  error
+}
+
+// NewPackedReader returns a PackedReader reading from r.
+func NewPackedReader(r io.Reader) *PackedReader {
+	return &PackedReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next ID of the stream, or io.EOF once its end is reached.
+// A stream cut short is reported as io.ErrUnexpectedEOF.
+func (p *PackedReader) Next() (XTID, error) {
+	if p.err != nil {
+		return Nil, p.err
+	}
+	if p.left == 0 {
+		n, err := binary.ReadUvarint(p.r)
+		if err != nil {
+			p.err = unexpectedEOF(err)
+			return Nil, p.err
+		}
+		if n == 0 {
+			p.err = io.EOF
+			return Nil, p.err
+		}
+		p.left = n
+	}
+
+	var b [byteLength]byte
+	if _, err := io.ReadFull(p.r, b[:]); err != nil {
+		p.err = unexpectedEOF(err)
+		return Nil, p.err
+	}
+	p.left--
+
+	id, err := FromBytes(b[:])
+	if err != nil {
+		p.err = err
+	}
+	return id, err
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// IDs is a list of XTIDs, which can be written and read as a packed stream.
+type IDs []XTID
+
+// WriteTo writes the IDs to w as a packed stream, see PackedWriter. It
+// implements io.WriterTo.
+func (ids IDs) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	p := NewPackedWriter(cw)
+	for _, id := range ids {
+		if err := p.Write(id); err != nil {
+			return cw.n, err
+		}
+	}
+	err := p.Close()
+	return cw.n, err
+}
+
+// ReadFrom appends the IDs of the packed stream read from r, see
+// PackedReader. It implements io.ReaderFrom. As reads are buffered, r may be
+// read past the end of the stream.
+func (ids *IDs) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	p := NewPackedReader(cr)
+	for {
+		id, err := p.Next()
+		if err == io.EOF {
+			return cr.n, nil
+		}
+		if err != nil {
+			return cr.n, err
+		}
+		*ids = append(*ids, id)
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,78 @@
+package xtid
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testIDs returns n IDs of increasing times and various types.
+func testIDs(n int) []XTID {
+	ids := make([]XTID, n)
+	t := Epoch().Add(1 << 40)
+	for j := range ids {
+		ids[j] = Must(defaultGenerator.Make(t.Add(time.Duration(j)*time.Millisecond), uint16(j%3)))
+	}
+	return ids
+}
+
+func TestPacked(t *testing.T) {
+	for _, n := range []int{0, 1, 100, packedBlockLength, packedBlockLength + 1} {
+		ids := IDs(testIDs(n))
+		var buf bytes.Buffer
+		written, err := ids.WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written != int64(buf.Len()) {
+			t.Errorf("%d IDs: WriteTo = %d, wrote %d bytes", n, written, buf.Len())
+		}
+		// Read past the end of the stream, as documented
+		buf.WriteString("trailer")
+
+		var got IDs
+		read, err := got.ReadFrom(&buf)
+		if err != nil {
+			t.Fatalf("%d IDs: %v", n, err)
+		}
+		if read < written {
+			t.Errorf("%d IDs: ReadFrom = %d, want at least %d", n, read, written)
+		}
+		if !slices.Equal(got, ids) {
+			t.Errorf("%d IDs: read %d different IDs", n, len(got))
+		}
+	}
+}
+
+func TestPackedErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := IDs(testIDs(3)).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	for _, l := range []int{0, 1, 2 * byteLength, len(b) - 1} {
+		var ids IDs
+		if _, err := ids.ReadFrom(bytes.NewReader(b[:l])); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("ReadFrom of %d bytes = %v, want %v", l, err, io.ErrUnexpectedEOF)
+		}
+	}
+
+	// Errors stick
+	r := NewPackedReader(bytes.NewReader(b[:1]))
+	for range 2 {
+		if _, err := r.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("Next = %v, want %v", err, io.ErrUnexpectedEOF)
+		}
+	}
+
+	w := NewPackedWriter(io.Discard)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(Nil); !errors.Is(err, errPackedClosed) {
+		t.Errorf("Write after Close = %v, want %v", err, errPackedClosed)
+	}
+}
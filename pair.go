@@ -0,0 +1,146 @@
+package xtid
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+const (
+	// Length of the binary form of a Pair
+	pairLength = 2 * byteLength
+	// Length of the string form of a Pair
+	pairEncodedLength = 2 * stringEncodedLength
+)
+
+var errPairSize = errors.New("xtid: invalid pair length")
+
+// A Pair is a key made of two XTIDs, as in the edge tables of graphs:
+// follower and followee, parent and child.
+//
+// Its binary form, 40 bytes, and its string form, 54 characters, are the
+// forms of A and B concatenated, and sort like ComparePairs orders pairs:
+// all the pairs of an A are contiguous, ordered by B.
+type Pair struct {
+	A, B XTID
+}
+
+// ComparePairs orders pairs by A, then by B.
+func ComparePairs(p, q Pair) int {
+	if c := Compare(p.A, q.A); c != 0 {
+		return c
+	}
+	return Compare(p.B, q.B)
+}
+
+// Reverse returns the pair of B and A, as stored by tables indexing edges
+// in both directions.
+func (p Pair) Reverse() Pair {
+	return Pair{A: p.B, B: p.A}
+}
+
+// IsNil reports whether both IDs are Nil.
+func (p Pair) IsNil() bool {
+	return p.A.IsNil() && p.B.IsNil()
+}
+
+// AppendBinary appends the binary form of p to b.
+func (p Pair) AppendBinary(b []byte) ([]byte, error) {
+	b = append(b, p.A[:]...)
+	return append(b, p.B[:]...), nil
+}
+
+func (p Pair) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, pairLength))
+}
+
+func (p *Pair) UnmarshalBinary(b []byte) error {
+	if len(b) != pairLength {
+		return errPairSize
+	}
+	a, err := FromBytes(b[:byteLength])
+	if err != nil {
+		return err
+	}
+	c, err := FromBytes(b[byteLength:])
+	if err != nil {
+		return err
+	}
+	p.A, p.B = a, c
+	return nil
+}
+
+// AppendText appends the string form of p to b.
+func (p Pair) AppendText(b []byte) ([]byte, error) {
+	return p.B.Append(p.A.Append(b)), nil
+}
+
+func (p Pair) String() string {
+	b, _ := p.AppendText(make([]byte, 0, pairEncodedLength))
+	return string(b)
+}
+
+func (p Pair) MarshalText() ([]byte, error) {
+	return p.AppendText(make([]byte, 0, pairEncodedLength))
+}
+
+func (p *Pair) UnmarshalText(b []byte) error {
+	q, err := ParsePair(string(b))
+	if err != nil {
+		return err
+	}
+	*p = q
+	return nil
+}
+
+// ParsePair decodes the string form of a Pair.
+func ParsePair(s string) (Pair, error) {
+	if len(s) != pairEncodedLength {
+		return Pair{}, errPairSize
+	}
+	a, err := Parse(s[:stringEncodedLength])
+	if err != nil {
+		return Pair{}, err
+	}
+	b, err := Parse(s[stringEncodedLength:])
+	if err != nil {
+		return Pair{}, err
+	}
+	return Pair{A: a, B: b}, nil
+}
+
+// Value implements the driver.Valuer interface, storing the pair in its
+// string form, or as NULL when both IDs are Nil.
+func (p Pair) Value() (driver.Value, error) {
+	if p.IsNil() {
+		return nil, nil
+	}
+	return p.String(), nil
+}
+
+// Scan implements the sql.Scanner interface. It accepts the binary and
+// string forms of a pair, as string or []byte. Like XTID.Scan, it maps nil
+// and empty input to the zero Pair.
+func (p *Pair) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("Scan: unable to scan type %T into Pair", v)
+	}
+	switch len(b) {
+	case 0:
+		*p = Pair{}
+		return nil
+	case pairLength:
+		return p.UnmarshalBinary(b)
+	case pairEncodedLength:
+		return p.UnmarshalText(b)
+	default:
+		return errPairSize
+	}
+}
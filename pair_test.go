@@ -0,0 +1,46 @@
+package xtid
+
+import (
+	"testing"
+)
+
+func TestPairScan(t *testing.T) {
+	pair := Pair{A: MustNew(1), B: MustNew(1)}
+	bin, err := pair.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		src  any
+		want Pair
+		ok   bool
+	}{
+		{"nil", nil, Pair{}, true},
+		{"empty bytes", []byte{}, Pair{}, true},
+		{"empty string", "", Pair{}, true},
+		{"binary", bin, pair, true},
+		{"string", pair.String(), pair, true},
+		{"string bytes", []byte(pair.String()), pair, true},
+		{"short", bin[:20], Pair{}, false},
+		{"other type", 42, Pair{}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// Scanning into a set pair resets it
+			p := Pair{A: MustNew(1)}
+			err := p.Scan(tt.src)
+			if (err == nil) != tt.ok {
+				t.Fatalf("Scan = %v, want ok %v", err, tt.ok)
+			}
+			if tt.ok && p != tt.want {
+				t.Errorf("Scan = %v, want %v", p, tt.want)
+			}
+		})
+	}
+
+	v, err := Pair{}.Value()
+	if err != nil || v != nil {
+		t.Errorf("Value of the zero Pair = %v, %v, want nil", v, err)
+	}
+}
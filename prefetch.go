@@ -0,0 +1,106 @@
+package xtid
+
+import (
+	"sync"
+	"time"
+)
+
+// How long a Prefetcher waits before retrying after failing to mint an ID
+const prefetchRetryDelay = 100 * time.Millisecond
+
+// A Prefetcher keeps a buffer of ready XTIDs topped up by a background
+// goroutine, so that latency-critical paths can take an ID without touching
+// the entropy source.
+//
+// Buffered IDs carry the time at which they were minted; Next skips those
+// older than the configured staleness bound, so timestamps remain accurate
+// to within that bound. The OnGenerate hooks see the IDs Next hands out, not
+// the ones it skips or Close drops.
+type Prefetcher struct {
+	gen    *Generator
+	typ    uint16
+	maxAge time.Duration
+
+	ids     chan XTID
+	stop    chan struct{}
+	done    chan struct{}
+	stopped sync.Once
+}
+
+// NewPrefetcher starts a Prefetcher buffering up to size IDs of type typ
+// minted by gen, or by the package-level generator when gen is nil. IDs older
+// than maxAge are never handed out. Close must be called to stop it.
+func NewPrefetcher(gen *Generator, typ uint16, size int, maxAge time.Duration) *Prefetcher {
+	if gen == nil {
+		gen = defaultGenerator
+	}
+	p := &Prefetcher{
+		gen:    gen,
+		typ:    typ,
+		maxAge: maxAge,
+		ids:    make(chan XTID, size),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go p.fill()
+	return p
+}
+
+func (p *Prefetcher) fill() {
+	defer close(p.done)
+
+	for {
+		id, err := p.gen.mint(time.Now(), p.typ)
+		if err != nil {
+			// Let Next report the error, and don't spin on a broken source
+			select {
+			case <-p.stop:
+				return
+			case <-time.After(prefetchRetryDelay):
+				continue
+			}
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case p.ids <- id:
+		}
+	}
+}
+
+// Next returns a buffered ID, or mints one directly when the buffer holds no
+// fresh ID.
+func (p *Prefetcher) Next() (XTID, error) {
+	for {
+		select {
+		case id := <-p.ids:
+			if p.fresh(id) {
+				notifyGenerate(id)
+				return id, nil
+			}
+		default:
+			return p.gen.NewWithType(p.typ)
+		}
+	}
+}
+
+// fresh reports whether id is no older than the staleness bound.
+func (p *Prefetcher) fresh(id XTID) bool {
+	// The generator may count from its own epoch, see WithEpoch
+	minted := timestampToTime(id.rawTimestamp(), p.gen.epochNanos())
+	// and round times down, in which case an ID minted now is as old as the
+	// current truncated time
+	now := time.Now()
+	if p.gen.truncate > 0 {
+		now = now.Truncate(p.gen.truncate)
+	}
+	return now.Sub(minted) <= p.maxAge
+}
+
+// Close stops the background goroutine and drops the buffered IDs. Further
+// calls do nothing.
+func (p *Prefetcher) Close() {
+	p.stopped.Do(func() { close(p.stop) })
+	<-p.done
+}
@@ -0,0 +1,72 @@
+package xtid
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPrefetcher(t *testing.T) {
+	// A type of its own, as hooks cannot be removed
+	const typ = 0x7e0
+	var generated atomic.Int32
+	OnGenerate(func(id XTID) {
+		if id.Type() == typ {
+			generated.Add(1)
+		}
+	})
+
+	p := NewPrefetcher(nil, typ, 16, time.Minute)
+	for len(p.ids) < cap(p.ids) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := generated.Load(); n != 0 {
+		t.Errorf("hooks called for %d buffered IDs", n)
+	}
+	for range 3 {
+		id, err := p.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id.Type() != typ {
+			t.Errorf("type %#x, want %#x", id.Type(), typ)
+		}
+	}
+	p.Close()
+	p.Close()
+	if n := generated.Load(); n != 3 {
+		t.Errorf("hooks called for %d IDs, want 3", n)
+	}
+}
+
+func TestPrefetcherFresh(t *testing.T) {
+	truncated, err := NewGenerator(WithTimeTruncation(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	for _, tt := range []struct {
+		name  string
+		gen   *Generator
+		t     time.Time
+		fresh bool
+	}{
+		{"now", defaultGenerator, now, true},
+		{"stale", defaultGenerator, now.Add(-2 * time.Minute), false},
+		// Up to an hour old, but as fresh as any ID the generator mints
+		{"truncated", truncated, now, true},
+		{"truncated stale", truncated, now.Add(-time.Hour), false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := tt.gen.mint(tt.t, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &Prefetcher{gen: tt.gen, maxAge: time.Minute}
+			if got := p.fresh(id); got != tt.fresh {
+				t.Errorf("fresh = %v, want %v", got, tt.fresh)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package xtid
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// WithTimeTruncation makes the generator round the time of every ID down to
+// a multiple of d, e.g. time.Minute or time.Hour, so that IDs handed out to
+// users don't reveal exactly when they were created. IDs stay sortable at
+// that granularity.
+func WithTimeTruncation(d time.Duration) Option {
+	return func(g *Generator) error {
+		if d <= 0 {
+			return fmt.Errorf("xtid: truncation must be positive, got %v", d)
+		}
+		g.truncate = d
+		return nil
+	}
+}
+
+// WithTimeJitter makes the generator move the time of every ID back by a
+// random duration of up to max, blurring the creation time while keeping IDs
+// roughly sortable and never ahead of the clock.
+//
+// The jittered times go back and forth, so NewGenerator rejects WithTimeJitter
+// combined with WithMonotonic, whose IDs would no longer be strictly
+// increasing, or with a ClockPolicy other than ClockIgnore, which would take
+// the jitter for clock regressions. WithTimeTruncation has no such issue.
+func WithTimeJitter(max time.Duration) Option {
+	return func(g *Generator) error {
+		if max <= 0 {
+			return fmt.Errorf("xtid: jitter must be positive, got %v", max)
+		}
+		g.jitter = max
+		return nil
+	}
+}
+
+// blur applies the time privacy options of g to t.
+func (g *Generator) blur(t time.Time) time.Time {
+	if g.jitter > 0 {
+		t = t.Add(-rand.N(g.jitter))
+	}
+	if g.truncate > 0 {
+		t = t.Truncate(g.truncate)
+	}
+	return t
+}
@@ -0,0 +1,27 @@
+package xtid
+
+import (
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// Bounds of the times of the IDs returned by Generate
+var (
+	quickMinTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	quickMaxTime = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Generate implements the testing/quick.Generator interface, returning
+// random valid XTIDs timestamped between 2000 and 2100.
+func (XTID) Generate(r *rand.Rand, size int) reflect.Value {
+	span := quickMaxTime.Sub(quickMinTime)
+	t := quickMinTime.Add(time.Duration(r.Int63n(int64(span))))
+
+	var id XTID
+	r.Read(id[payloadStart:])
+	id = id.withRawTimestamp(timeToCorrectedUTCTimestamp(t))
+	id[timestampLengthInBytes] = byte(r.Intn(256))
+	id[timestampLengthInBytes+1] = byte(r.Intn(256))
+	return reflect.ValueOf(id)
+}
@@ -0,0 +1,98 @@
+package xtid
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by RateLimiter.TryNewWithType when the ID
+// budget is exhausted.
+var ErrRateLimited = errors.New("xtid: ID rate limit exceeded")
+
+// A RateLimiter wraps a Generator, capping the number of IDs it mints per
+// second with a token bucket.
+type RateLimiter struct {
+	gen   *Generator
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing perSecond IDs per second on
+// average and bursts of up to burst IDs, minted by gen or by the
+// package-level generator when gen is nil.
+func NewRateLimiter(gen *Generator, perSecond float64, burst int) *RateLimiter {
+	if gen == nil {
+		gen = defaultGenerator
+	}
+	return &RateLimiter{
+		gen:    gen,
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token, returning how long the caller must wait before it
+// becomes available. With wait false no token is taken unless one is
+// available right away.
+func (r *RateLimiter) reserve(wait bool) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+
+	if r.tokens >= 1 {
+		r.tokens--
+		return 0, true
+	}
+	if !wait || r.rate <= 0 {
+		return 0, false
+	}
+	r.tokens--
+	return time.Duration((-r.tokens) / r.rate * float64(time.Second)), true
+}
+
+// cancel gives back a token taken by reserve.
+func (r *RateLimiter) cancel() {
+	r.mu.Lock()
+	r.tokens++
+	r.mu.Unlock()
+}
+
+// NewWithType blocks until the rate limit allows minting a new XTID of type
+// typ, or until ctx is done.
+func (r *RateLimiter) NewWithType(ctx context.Context, typ uint16) (XTID, error) {
+	d, ok := r.reserve(true)
+	if !ok {
+		return Nil, ErrRateLimited
+	}
+	if d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			r.cancel()
+			return Nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+	return r.gen.NewWithType(typ)
+}
+
+// TryNewWithType mints a new XTID of type typ if the rate limit allows it
+// right away, and fails with ErrRateLimited otherwise.
+func (r *RateLimiter) TryNewWithType(typ uint16) (XTID, error) {
+	if _, ok := r.reserve(false); !ok {
+		return Nil, ErrRateLimited
+	}
+	return r.gen.NewWithType(typ)
+}
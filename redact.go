@@ -0,0 +1,37 @@
+package xtid
+
+import (
+	"log/slog"
+)
+
+// The number of leading base62 characters kept by Redacted. As 62^14 is
+// larger than 2^80, they are derived from the timestamp and type of the ID,
+// not its payload.
+const redactedPrefixLength = 13
+
+// Redacted returns a representation of the ID fit for logs, which keeps the
+// leading characters, derived from its timestamp and type, and masks those
+// derived from the payload:
+//
+//	0D0c7D1MqJPRy-****
+//
+// Use it for IDs doubling as capabilities, which must not appear in full in
+// logs. Redacted representations can't be passed through Parse.
+func (i XTID) Redacted() string {
+	s := i.String()
+	return s[:redactedPrefixLength] + "-****"
+}
+
+// RedactedLogValuer wraps a XTID to log it redacted with log/slog:
+//
+//	slog.Info("password reset", "token", xtid.RedactedLogValuer(id))
+type RedactedLogValuer XTID
+
+// LogValue implements slog.LogValuer.
+func (r RedactedLogValuer) LogValue() slog.Value {
+	return slog.StringValue(XTID(r).Redacted())
+}
+
+func (r RedactedLogValuer) String() string {
+	return XTID(r).Redacted()
+}
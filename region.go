@@ -0,0 +1,73 @@
+package xtid
+
+import (
+	"fmt"
+)
+
+// WithRegion stores region, the code of the region or datacenter the
+// generator runs in, in the last byte of the payload, so that globally
+// replicated systems can tell where an ID was minted. Use XTID.Region to
+// extract it, and RegisterRegion to name the codes.
+//
+// Unlike the leading bytes reserved by WithNodeID and WithTenant, the region
+// byte does not affect the order of the IDs of a generator, and it is kept
+// by WithMonotonic, which increments the bytes before it. With the leading
+// bytes, it must leave at least one random byte, or NewGenerator fails.
+func WithRegion(region uint8) Option {
+	return func(g *Generator) error {
+		if len(g.suffix) != 0 {
+			return fmt.Errorf("xtid: region already set")
+		}
+		g.suffix = []byte{region}
+		return nil
+	}
+}
+
+// Region returns the region code stored in the ID by a Generator configured
+// with WithRegion. It is meaningless for other IDs.
+func (i XTID) Region() uint8 {
+	return i[byteLength-1]
+}
+
+// RegisterRegion names region code region. It fails if region or name are
+// already registered.
+func (r *Registry) RegisterRegion(region uint8, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if old, ok := r.regions[region]; ok {
+		return fmt.Errorf("xtid: region %d already registered as %q", region, old)
+	}
+	for code, old := range r.regions {
+		if old == name {
+			return fmt.Errorf("xtid: region name %q already registered for region %d", name, code)
+		}
+	}
+	r.regions[region] = name
+	return nil
+}
+
+// RegionName returns the name registered for region code region.
+func (r *Registry) RegionName(region uint8) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.regions[region]
+	return name, ok
+}
+
+// LookupRegion returns the region code registered under name.
+func (r *Registry) LookupRegion(name string) (uint8, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for code, old := range r.regions {
+		if old == name {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// RegisterRegion names region code region in DefaultRegistry.
+func RegisterRegion(region uint8, name string) error {
+	return DefaultRegistry.RegisterRegion(region, name)
+}
@@ -0,0 +1,113 @@
+package xtid
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A Registry names XTID types, and the namespaces grouping them, so that
+// they can be displayed and looked up by name, and so that conflicting
+// assignments are caught early. Registries are safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	types      map[uint16]string
+	names      map[string]uint16
+	namespaces map[uint8]string
+	regions    map[uint8]string
+
+	// Number of low bits of the types used as flags, and their names
+	flagBits uint
+	flags    map[uint16]string
+
+	reservations []Reservation
+	// The owners of the types registered with RegisterFor
+	owners map[uint16]string
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		types:      make(map[uint16]string),
+		names:      make(map[string]uint16),
+		namespaces: make(map[uint8]string),
+		regions:    make(map[uint8]string),
+		flags:      make(map[uint16]string),
+		owners:     make(map[uint16]string),
+	}
+}
+
+// DefaultRegistry is the registry used by the package-level functions.
+var DefaultRegistry = NewRegistry()
+
+// Register names type typ. It fails if typ or name are already registered,
+// or if typ is reserved, see Reserve.
+func (r *Registry) Register(typ uint16, name string) error {
+	return r.register(typ, name, "")
+}
+
+func (r *Registry) register(typ uint16, name, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, reserved := r.reservation(typ)
+	switch {
+	case reserved && res.Owner != owner:
+		return fmt.Errorf("%w: type %d (%s) is reserved by %v", ErrReserved, typ, name, res)
+	case !reserved && owner != "":
+		return fmt.Errorf("%w: type %d (%s) is not reserved for %q", ErrReserved, typ, name, owner)
+	}
+	if typ&r.flagMask() != 0 {
+		return fmt.Errorf("xtid: type %d (%s) has flag bits set", typ, name)
+	}
+	if old, ok := r.types[typ]; ok {
+		return fmt.Errorf("xtid: type %d already registered as %q", typ, old)
+	}
+	if old, ok := r.names[name]; ok {
+		return fmt.Errorf("xtid: type name %q already registered for type %d", name, old)
+	}
+	r.types[typ] = name
+	r.names[name] = typ
+	if owner != "" {
+		r.owners[typ] = owner
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics on error. It is meant for
+// package initialization.
+func (r *Registry) MustRegister(typ uint16, name string) {
+	if err := r.Register(typ, name); err != nil {
+		panic(err)
+	}
+}
+
+// Name returns the name registered for type typ, ignoring its flags, see
+// SetFlagBits.
+func (r *Registry) Name(typ uint16) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.types[typ&^r.flagMask()]
+	return name, ok
+}
+
+// Lookup returns the type registered under name.
+func (r *Registry) Lookup(name string) (uint16, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	typ, ok := r.names[name]
+	return typ, ok
+}
+
+// RegisterType names type typ in DefaultRegistry.
+func RegisterType(typ uint16, name string) error {
+	return DefaultRegistry.Register(typ, name)
+}
+
+// TypeName returns the name of type typ in DefaultRegistry, or its number
+// when it is not registered.
+func TypeName(typ uint16) string {
+	if name, ok := DefaultRegistry.Name(typ); ok {
+		return name
+	}
+	return fmt.Sprint(typ)
+}
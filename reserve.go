@@ -0,0 +1,122 @@
+package xtid
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ErrReserved matches, through errors.Is, the errors caused by types
+// registered against the reservations of a Registry.
+var ErrReserved = errors.New("xtid: type range reservation conflict")
+
+// A Reservation assigns a range of types to an owner, such as a team.
+type Reservation struct {
+	// Min and Max are the first and last types of the range.
+	Min, Max uint16
+	Owner    string
+}
+
+// Contains reports whether typ lies within the range.
+func (res Reservation) Contains(typ uint16) bool {
+	return res.Min <= typ && typ <= res.Max
+}
+
+func (res Reservation) String() string {
+	return fmt.Sprintf("%#04x-%#04x (%s)", res.Min, res.Max, res.Owner)
+}
+
+// Reserve assigns the types from lo to hi included to owner, so that only
+// RegisterFor with the same owner can register them:
+//
+//	xtid.DefaultRegistry.MustReserve(0x0100, 0x01ff, "payments")
+//
+// It fails if the range overlaps another reservation. Reservations are best
+// declared in a package shared by the services, imported before the types
+// are registered; Check catches the types registered in the range before
+// it was reserved.
+func (r *Registry) Reserve(lo, hi uint16, owner string) error {
+	if lo > hi || owner == "" {
+		return fmt.Errorf("xtid: invalid reservation %#04x-%#04x for %q", lo, hi, owner)
+	}
+	res := Reservation{Min: lo, Max: hi, Owner: owner}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, other := range r.reservations {
+		if other.Min <= hi && lo <= other.Max {
+			return fmt.Errorf("%w: %v overlaps %v", ErrReserved, res, other)
+		}
+	}
+	r.reservations = append(r.reservations, res)
+	return nil
+}
+
+// MustReserve is like Reserve but panics on error.
+func (r *Registry) MustReserve(lo, hi uint16, owner string) {
+	if err := r.Reserve(lo, hi, owner); err != nil {
+		panic(err)
+	}
+}
+
+// reservation returns the reservation holding typ. The caller must hold
+// r.mu.
+func (r *Registry) reservation(typ uint16) (Reservation, bool) {
+	for _, res := range r.reservations {
+		if res.Contains(typ) {
+			return res, true
+		}
+	}
+	return Reservation{}, false
+}
+
+// RegisterFor names type typ on behalf of owner, which must have reserved
+// it. It fails like Register otherwise.
+func (r *Registry) RegisterFor(owner string, typ uint16, name string) error {
+	return r.register(typ, name, owner)
+}
+
+// MustRegisterFor is like RegisterFor but panics on error.
+func (r *Registry) MustRegisterFor(owner string, typ uint16, name string) {
+	if err := r.RegisterFor(owner, typ, name); err != nil {
+		panic(err)
+	}
+}
+
+// Reservations returns the reservations, ordered by range.
+func (r *Registry) Reservations() []Reservation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := slices.Clone(r.reservations)
+	slices.SortFunc(res, func(a, b Reservation) int { return int(a.Min) - int(b.Min) })
+	return res
+}
+
+// Check reports the types registered against the reservations, which can
+// only happen when they were registered before the reservation was made.
+// It is meant to be called once on startup, after all the registrations.
+func (r *Registry) Check() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var errs []error
+	for _, typ := range slices.Sorted(maps.Keys(r.types)) {
+		if res, ok := r.reservation(typ); ok && r.owners[typ] != res.Owner {
+			errs = append(errs, fmt.Errorf("%w: type %d (%s) is registered within %v", ErrReserved, typ, r.types[typ], res))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reserve assigns a range of types to owner in DefaultRegistry.
+func Reserve(lo, hi uint16, owner string) error {
+	return DefaultRegistry.Reserve(lo, hi, owner)
+}
+
+// RegisterTypeFor names type typ on behalf of owner in DefaultRegistry.
+func RegisterTypeFor(owner string, typ uint16, name string) error {
+	return DefaultRegistry.RegisterFor(owner, typ, name)
+}
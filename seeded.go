@@ -0,0 +1,147 @@
+package xtid
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Length of the seeds fetched by a SeededSource
+const seedLength = 32
+
+// Defaults of SeedPolicy
+const (
+	defaultSeedBytes   = 1 << 20
+	defaultSeedMaxAge  = time.Hour
+	defaultSeedTimeout = 10 * time.Second
+)
+
+// A SeedFetcher fetches seed material from an external source, such as the
+// GenerateRandom call of a KMS or the RNG of an HSM.
+//
+// FetchSeed must fill all of p, or fail.
+type SeedFetcher interface {
+	FetchSeed(ctx context.Context, p []byte) error
+}
+
+// SeedFetcherFunc adapts a function to the SeedFetcher interface.
+type SeedFetcherFunc func(ctx context.Context, p []byte) error
+
+func (f SeedFetcherFunc) FetchSeed(ctx context.Context, p []byte) error {
+	return f(ctx, p)
+}
+
+// A SeedPolicy tells a SeededSource when to fetch a new seed. Zero fields
+// take their default.
+type SeedPolicy struct {
+	// Bytes is the number of bytes generated from a seed, 1 MiB by default.
+	Bytes int
+	// MaxAge is how long a seed is used, one hour by default.
+	MaxAge time.Duration
+	// Timeout bounds every fetch, 10 seconds by default.
+	Timeout time.Duration
+}
+
+// A SeededSource generates random bytes in userspace with a ChaCha8 stream
+// whose seeds all come from a SeedFetcher, for environments requiring the
+// seeds of identifier randomness to come from an attested KMS or HSM. It is
+// safe for concurrent use, and implements PayloadSource.
+//
+// The stream is reseeded when its policy says so. The fetch then runs
+// synchronously, blocking the IDs minted meanwhile, and a failure fails
+// them: the source never falls back to local entropy nor keeps using an
+// expired seed.
+type SeededSource struct {
+	fetcher SeedFetcher
+	policy  SeedPolicy
+
+	mu       sync.Mutex
+	rng      *rand.ChaCha8
+	left     int
+	seededAt time.Time
+}
+
+// NewSeededSource returns a source seeded by fetcher according to policy.
+// It fetches the first seed with ctx, failing if it can't.
+//
+//	src, err := xtid.NewSeededSource(ctx, kmsFetcher, xtid.SeedPolicy{MaxAge: 15 * time.Minute})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	xtid.SetSource(src)
+func NewSeededSource(ctx context.Context, fetcher SeedFetcher, policy SeedPolicy) (*SeededSource, error) {
+	if fetcher == nil {
+		return nil, fmt.Errorf("xtid: nil seed fetcher")
+	}
+	if policy.Bytes <= 0 {
+		policy.Bytes = defaultSeedBytes
+	}
+	if policy.MaxAge <= 0 {
+		policy.MaxAge = defaultSeedMaxAge
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = defaultSeedTimeout
+	}
+	s := &SeededSource{fetcher: fetcher, policy: policy}
+	if err := s.Reseed(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reseed fetches a new seed with ctx and restarts the stream from it, as
+// when a seed expires. On failure the source keeps its current seed.
+func (s *SeededSource) Reseed(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reseed(ctx)
+}
+
+func (s *SeededSource) reseed(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.policy.Timeout)
+	defer cancel()
+
+	var seed [seedLength]byte
+	defer clear(seed[:])
+	if err := s.fetcher.FetchSeed(ctx, seed[:]); err != nil {
+		return fmt.Errorf("xtid: fetching seed: %w", err)
+	}
+	s.rng = rand.NewChaCha8(seed)
+	s.left = s.policy.Bytes
+	s.seededAt = time.Now()
+	return nil
+}
+
+// SeededAt returns the time the current seed was fetched.
+func (s *SeededSource) SeededAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seededAt
+}
+
+func (s *SeededSource) Read(p []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for n < len(p) {
+		if s.left == 0 || time.Since(s.seededAt) >= s.policy.MaxAge {
+			if err = s.reseed(context.Background()); err != nil {
+				return
+			}
+		}
+
+		c := min(len(p)-n, s.left)
+		s.rng.Read(p[n : n+c])
+		s.left -= c
+		n += c
+	}
+
+	return
+}
+
+func (s *SeededSource) FillPayload(p *[payloadLengthInBytes]byte) error {
+	_, err := s.Read(p[:])
+	return err
+}
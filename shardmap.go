@@ -0,0 +1,134 @@
+package xtid
+
+import (
+	"encoding/binary"
+	"iter"
+	"math/bits"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// A ShardedMap is a concurrent map keyed by XTIDs, for registries such as ID
+// to session maps which see many lookups from many goroutines. It stripes
+// its entries over shards guarded by their own locks, picking the shard of
+// an ID from its payload, which is random already, rather than hashing it.
+// The zero value is not usable, see NewShardedMap.
+type ShardedMap[V any] struct {
+	shards []mapShard[V]
+	shift  uint
+}
+
+type mapShard[V any] struct {
+	mu sync.RWMutex
+	m  map[XTID]V
+	// Pads the shards to 64 bytes, a cache line on most CPUs, so that the
+	// locks of neighboring shards don't share one. A map is a pointer.
+	_ [64 - unsafe.Sizeof(sync.RWMutex{}) - unsafe.Sizeof(uintptr(0))]byte
+}
+
+// NewShardedMap returns an empty ShardedMap with n shards, rounded up to a
+// power of two, or four per CPU when n is not positive.
+func NewShardedMap[V any](n int) *ShardedMap[V] {
+	if n <= 0 {
+		n = 4 * runtime.GOMAXPROCS(0)
+	}
+	b := uint(bits.Len(uint(n - 1)))
+	m := &ShardedMap[V]{shards: make([]mapShard[V], 1<<b), shift: 64 - b}
+	for j := range m.shards {
+		m.shards[j].m = make(map[XTID]V)
+	}
+	return m
+}
+
+// shard returns the shard of id. The payload is folded and multiplied by
+// 2^64/phi, so that IDs sharing leading payload bytes, as set by
+// WithNodeID, or trailing ones, as set by WithRegion, still spread evenly.
+func (m *ShardedMap[V]) shard(id *XTID) *mapShard[V] {
+	h := binary.BigEndian.Uint64(id[byteLength-8:]) ^ uint64(binary.BigEndian.Uint16(id[payloadStart:]))<<48
+	h = (h ^ h>>29) * 0x9e3779b97f4a7c15
+	if m.shift == 64 {
+		return &m.shards[0]
+	}
+	return &m.shards[h>>m.shift]
+}
+
+// Load returns the value stored for id, if any.
+func (m *ShardedMap[V]) Load(id XTID) (V, bool) {
+	s := m.shard(&id)
+	s.mu.RLock()
+	v, ok := s.m[id]
+	s.mu.RUnlock()
+	return v, ok
+}
+
+// Store sets the value for id.
+func (m *ShardedMap[V]) Store(id XTID, v V) {
+	s := m.shard(&id)
+	s.mu.Lock()
+	s.m[id] = v
+	s.mu.Unlock()
+}
+
+// LoadOrStore returns the value stored for id if any, and otherwise stores
+// v. It reports whether the value was loaded.
+func (m *ShardedMap[V]) LoadOrStore(id XTID, v V) (V, bool) {
+	s := m.shard(&id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.m[id]; ok {
+		return old, true
+	}
+	s.m[id] = v
+	return v, false
+}
+
+// LoadAndDelete deletes the value for id, returning it if there was one.
+func (m *ShardedMap[V]) LoadAndDelete(id XTID) (V, bool) {
+	s := m.shard(&id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.m[id]
+	delete(s.m, id)
+	return v, ok
+}
+
+// Delete deletes the value for id.
+func (m *ShardedMap[V]) Delete(id XTID) {
+	s := m.shard(&id)
+	s.mu.Lock()
+	delete(s.m, id)
+	s.mu.Unlock()
+}
+
+// Len returns the number of entries. It is only a snapshot under
+// concurrent updates.
+func (m *ShardedMap[V]) Len() int {
+	n := 0
+	for j := range m.shards {
+		s := &m.shards[j]
+		s.mu.RLock()
+		n += len(s.m)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// All returns a sequence of the entries, in no particular order. Each shard
+// is read locked while its entries are yielded, so the loop body must not
+// update the map.
+func (m *ShardedMap[V]) All() iter.Seq2[XTID, V] {
+	return func(yield func(XTID, V) bool) {
+		for j := range m.shards {
+			s := &m.shards[j]
+			s.mu.RLock()
+			for id, v := range s.m {
+				if !yield(id, v) {
+					s.mu.RUnlock()
+					return
+				}
+			}
+			s.mu.RUnlock()
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !tinygo && !js && !wasip1
+
+package xtid
+
+import (
+	"io"
+)
+
+// defaultSource returns crypto/rand, buffered by a pool of per-P chunks.
+func defaultSource() io.Reader {
+	return newEntropyPool()
+}
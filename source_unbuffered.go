@@ -0,0 +1,16 @@
+//go:build tinygo || js || wasip1
+
+package xtid
+
+import (
+	"crypto/rand"
+	"io"
+)
+
+// defaultSource returns crypto/rand as is on TinyGo and WebAssembly, where
+// programs mostly run on a single thread and the pool buffering it elsewhere
+// brings nothing but memory use. Use SetSource with an EntropyFunc to draw
+// from the host instead.
+func defaultSource() io.Reader {
+	return rand.Reader
+}
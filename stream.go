@@ -0,0 +1,116 @@
+package xtid
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Number of IDs whose random bytes Stream reads at once
+const streamBatchSize = 64
+
+// Stream returns a sequence of new XTIDs of type typ, minted by the generator
+// carried by ctx (see FromContext), ending like Generator.Stream. Carry a
+// generator using WithMonotonic for strictly increasing IDs.
+func Stream(ctx context.Context, typ uint16) iter.Seq2[XTID, error] {
+	return FromContext(ctx).Stream(ctx, typ)
+}
+
+// StreamChan is the channel-based variant of Stream, see
+// Generator.StreamChan.
+func StreamChan(ctx context.Context, typ uint16, buf int) (<-chan XTID, <-chan error) {
+	return FromContext(ctx).StreamChan(ctx, typ, buf)
+}
+
+// Stream returns a sequence of new XTIDs of type typ, paired with nil
+// errors:
+//
+//	for id, err := range gen.Stream(ctx, typ) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// The sequence ends without an error when ctx is cancelled. When minting an
+// ID fails, it ends after yielding Nil with the error, such as an
+// EntropyError or a ClockRegressionError.
+func (g *Generator) Stream(ctx context.Context, typ uint16) iter.Seq2[XTID, error] {
+	if g.batchable() {
+		return g.streamBatched(ctx, typ)
+	}
+	return func(yield func(XTID, error) bool) {
+		for ctx.Err() == nil {
+			id, err := g.NewWithType(typ)
+			if err != nil {
+				yield(Nil, err)
+				return
+			}
+			if !yield(id, nil) {
+				return
+			}
+		}
+	}
+}
+
+// streamBatched is Stream reading the random bytes of streamBatchSize IDs at
+// once. The IDs are still stamped with the time they are handed out.
+func (g *Generator) streamBatched(ctx context.Context, typ uint16) iter.Seq2[XTID, error] {
+	return func(yield func(XTID, error) bool) {
+		size := g.randomLength()
+		buf := make([]byte, streamBatchSize*size)
+		defer clear(buf)
+		rnd := buf[len(buf):]
+		for ctx.Err() == nil {
+			if len(rnd) == 0 {
+				if err := g.readRandom(typ, buf); err != nil {
+					yield(Nil, err)
+					return
+				}
+				rnd = buf
+			}
+			id, err := g.makeFrom(time.Now(), typ, rnd)
+			rnd = rnd[size:]
+			if err != nil {
+				yield(Nil, err)
+				return
+			}
+			if !yield(id, nil) {
+				return
+			}
+		}
+	}
+}
+
+// StreamChan is the channel-based variant of Stream. The channel of IDs has
+// a buffer of size buf, and is closed when ctx is cancelled or minting an
+// ID fails. The error channel then receives the error of the failure, if
+// any, and is closed:
+//
+//	ids, errc := gen.StreamChan(ctx, typ, 64)
+//	for id := range ids {
+//		...
+//	}
+//	if err := <-errc; err != nil {
+//		return err
+//	}
+func (g *Generator) StreamChan(ctx context.Context, typ uint16, buf int) (<-chan XTID, <-chan error) {
+	ch := make(chan XTID, buf)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(ch)
+		for id, err := range g.Stream(ctx, typ) {
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case ch <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, errc
+}
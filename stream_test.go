@@ -0,0 +1,117 @@
+package xtid
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+// failingReader returns n random bytes, then fails.
+type failingReader struct{ n int }
+
+var errSource = errors.New("source failed")
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, errSource
+	}
+	p = p[:min(len(p), r.n)]
+	r.n -= len(p)
+	return rand.Read(p)
+}
+
+func TestStream(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []Option
+	}{
+		{"batched", nil},
+		{"monotonic", []Option{WithMonotonic()}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			gen, err := NewGenerator(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			n := 0
+			for id, err := range gen.Stream(ctx, 3) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if id.Type() != 3 {
+					t.Fatalf("type %d, want 3", id.Type())
+				}
+				if n++; n == 200 {
+					cancel()
+				}
+			}
+			if n != 200 {
+				t.Errorf("%d IDs after cancellation at 200", n)
+			}
+		})
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []Option
+	}{
+		{"batched", nil},
+		{"monotonic", []Option{WithMonotonic()}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithSource(&failingReader{n: 10 * 300})}, tt.opts...)
+			gen, err := NewGenerator(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var last error
+			n := 0
+			for id, err := range gen.Stream(context.Background(), 1) {
+				if last != nil {
+					t.Fatal("stream went on after an error")
+				}
+				if err != nil {
+					if id != Nil {
+						t.Errorf("ID %s with error", id)
+					}
+					last = err
+					continue
+				}
+				n++
+			}
+			if !errors.Is(last, errSource) {
+				t.Errorf("stream ended with %v after %d IDs, want %v", last, n, errSource)
+			}
+		})
+	}
+}
+
+func TestStreamChan(t *testing.T) {
+	gen, err := NewGenerator(WithSource(&failingReader{n: 10 * 100}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, errc := gen.StreamChan(context.Background(), 1, 8)
+	n := 0
+	for range ids {
+		n++
+	}
+	if err := <-errc; !errors.Is(err, errSource) {
+		t.Errorf("error %v after %d IDs, want %v", err, n, errSource)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ids, errc = StreamChan(ctx, 1, 0)
+	<-ids
+	cancel()
+	for range ids {
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("error %v after cancellation", err)
+	}
+}
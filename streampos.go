@@ -0,0 +1,122 @@
+package xtid
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+const (
+	// Length of the binary form of a StreamPosition
+	streamPositionLength = byteLength + 8
+	// Number of digits of the sequence in the string form of a
+	// StreamPosition, enough for any uint64
+	streamSeqDigits = 20
+	// Length of the string form of a StreamPosition
+	streamPositionEncodedLength = stringEncodedLength + 1 + streamSeqDigits
+)
+
+var (
+	errStreamPositionSize = errors.New("xtid: invalid stream position length")
+	errStreamPosition     = errors.New("xtid: invalid stream position")
+)
+
+// A StreamPosition identifies an event within an event stream: the XTID of
+// the stream, and the sequence number of the event within it.
+//
+// Its binary form, the stream ID followed by the big-endian sequence, and
+// its string form, the stream ID and the zero-padded decimal sequence
+// separated by a colon, both sort like Compare orders positions:
+//
+//	00DdjxnD9QhSu715j5vwl3AE8YK:00000000000000000042
+type StreamPosition struct {
+	Stream XTID
+	Seq    uint64
+}
+
+// ComparePositions orders positions by stream, then by sequence.
+func ComparePositions(a, b StreamPosition) int {
+	if c := Compare(a.Stream, b.Stream); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Seq, b.Seq)
+}
+
+// Next returns the position following p in its stream.
+func (p StreamPosition) Next() StreamPosition {
+	return StreamPosition{Stream: p.Stream, Seq: p.Seq + 1}
+}
+
+// AppendBinary appends the binary form of p to b.
+func (p StreamPosition) AppendBinary(b []byte) ([]byte, error) {
+	b = append(b, p.Stream[:]...)
+	return binary.BigEndian.AppendUint64(b, p.Seq), nil
+}
+
+func (p StreamPosition) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(make([]byte, 0, streamPositionLength))
+}
+
+func (p *StreamPosition) UnmarshalBinary(b []byte) error {
+	if len(b) != streamPositionLength {
+		return errStreamPositionSize
+	}
+	id, err := FromBytes(b[:byteLength])
+	if err != nil {
+		return err
+	}
+	p.Stream, p.Seq = id, binary.BigEndian.Uint64(b[byteLength:])
+	return nil
+}
+
+// AppendText appends the string form of p to b.
+func (p StreamPosition) AppendText(b []byte) ([]byte, error) {
+	b = p.Stream.Append(b)
+	b = append(b, ':')
+	var digits [streamSeqDigits]byte
+	seq := strconv.AppendUint(digits[:0], p.Seq, 10)
+	for range streamSeqDigits - len(seq) {
+		b = append(b, '0')
+	}
+	return append(b, seq...), nil
+}
+
+func (p StreamPosition) String() string {
+	b, _ := p.AppendText(make([]byte, 0, streamPositionEncodedLength))
+	return string(b)
+}
+
+func (p StreamPosition) MarshalText() ([]byte, error) {
+	return p.AppendText(make([]byte, 0, streamPositionEncodedLength))
+}
+
+func (p *StreamPosition) UnmarshalText(b []byte) error {
+	q, err := ParseStreamPosition(string(b))
+	if err != nil {
+		return err
+	}
+	*p = q
+	return nil
+}
+
+// ParseStreamPosition decodes the string form of a StreamPosition. The
+// sequence may lack its leading zeros.
+func ParseStreamPosition(s string) (StreamPosition, error) {
+	if len(s) < stringEncodedLength+2 || len(s) > streamPositionEncodedLength || s[stringEncodedLength] != ':' {
+		return StreamPosition{}, errStreamPosition
+	}
+	id, err := Parse(s[:stringEncodedLength])
+	if err != nil {
+		return StreamPosition{}, err
+	}
+	digits := s[stringEncodedLength+1:]
+	if digits[0] == '+' {
+		return StreamPosition{}, errStreamPosition
+	}
+	seq, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return StreamPosition{}, errStreamPosition
+	}
+	return StreamPosition{Stream: id, Seq: seq}, nil
+}
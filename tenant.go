@@ -0,0 +1,56 @@
+package xtid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+)
+
+// WithTenant reserves the first size bytes of the payload, between 2 and 4,
+// for a tag derived from the name of tenant, see TenantHash, so that storage
+// layers can route or partition rows by tenant from their IDs alone. Use
+// XTID.TenantTag with the same size to extract it.
+//
+// Tags of distinct tenants collide with a probability of 2^(-8*size): they
+// are fit for routing, not for access control. The option uses the leading
+// payload bytes like WithNodeID, and fails if they are already reserved.
+func WithTenant(tenant string, size int) Option {
+	return func(g *Generator) error {
+		if len(g.prefix) != 0 {
+			return errLeadingBytes
+		}
+		if size < 2 || size > 4 {
+			return fmt.Errorf("xtid: tenant tag size must be between 2 and 4 bytes, got %d", size)
+		}
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], TenantHash(tenant, size))
+		g.prefix = append(g.prefix, b[4-size:]...)
+		return nil
+	}
+}
+
+// TenantHash returns the tag of size bytes of tenant embedded by
+// WithTenant: the low bytes of its 32-bit FNV-1a hash.
+func TenantHash(tenant string, size int) uint32 {
+	if size < 1 || size > 4 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(tenant))
+	return h.Sum32() & (1<<(8*size) - 1)
+}
+
+// TenantTag returns the tenant tag stored in the first size bytes of the
+// payload by a Generator configured with WithTenant.
+func (i XTID) TenantTag(size int) uint32 {
+	if size < 1 || size > 4 {
+		return 0
+	}
+	return uint32(i.NodeID(size))
+}
+
+// HasTenant reports whether the ID carries the tag of tenant, minted by a
+// Generator configured with WithTenant(tenant, size).
+func (i XTID) HasTenant(tenant string, size int) bool {
+	return i.TenantTag(size) == TenantHash(tenant, size)
+}
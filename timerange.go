@@ -0,0 +1,85 @@
+package xtid
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeRange is matched by the *TimeRangeError returned by Make and
+// generators for times their IDs can't carry.
+var ErrTimeRange = errors.New("xtid: time out of range")
+
+// TimeRangeError is returned when minting an ID for a time before the epoch,
+// such as the zero Time, past the largest timestamp of the ID, or further in
+// the future than allowed by WithMaxFuture.
+type TimeRangeError struct {
+	Time     time.Time
+	Min, Max time.Time
+}
+
+func (e *TimeRangeError) Error() string {
+	return fmt.Sprintf("xtid: time %v out of range [%v, %v]", e.Time, e.Min, e.Max)
+}
+
+func (e *TimeRangeError) Is(target error) bool {
+	return target == ErrTimeRange
+}
+
+// WithMaxFuture makes the generator reject times more than d after the
+// current time, which usually betray a bug upstream, as such IDs would sort
+// after every ID minted until then.
+func WithMaxFuture(d time.Duration) Option {
+	return func(g *Generator) error {
+		if d < 0 {
+			return fmt.Errorf("xtid: max future must not be negative, got %v", d)
+		}
+		g.maxFuture = d
+		return nil
+	}
+}
+
+// checkTime fails with a *TimeRangeError if t can't be the time of the IDs
+// minted by g.
+func (g *Generator) checkTime(t time.Time) error {
+	e := g.epochNanos()
+	lo := time.Unix(0, e)
+	var hi time.Time
+	if g.nano {
+		hi = time.Unix(0, e).Add(timestampMask * time.Nanosecond)
+		if hi.Before(lo) {
+			// The duration overflows past 2262, the limit of UnixNano
+			hi = time.Unix(0, 1<<63-1)
+		}
+	} else {
+		hi = time.UnixMicro(e/1e3 + timestampMask)
+	}
+	if g.maxFuture > 0 {
+		if limit := time.Now().Add(g.maxFuture); limit.Before(hi) {
+			hi = limit
+		}
+	}
+	if t.Before(lo) || t.After(hi) {
+		return &TimeRangeError{Time: t, Min: lo.UTC(), Max: hi.UTC()}
+	}
+	return nil
+}
+
+// LowerBound returns the smallest ID of microsecond precision minted at t,
+// whatever its type and payload: the inclusive lower bound of the IDs minted
+// from t on, and the exclusive upper bound of those minted before t, in
+// range queries over stored IDs. Unlike MakeWithPayload, it clamps t to the
+// range of timestamps: times before the epoch, such as the zero Time, give
+// the smallest ID, and times past the largest timestamp give the smallest ID
+// of nanosecond precision, which sorts after all of them.
+func LowerBound(t time.Time) (id XTID) {
+	e := epoch
+	switch {
+	case t.Before(time.Unix(0, e)):
+	case t.After(time.UnixMicro(e/1e3 + timestampMask)):
+		id = id.withRawTimestamp(versionNano << versionShift)
+	default:
+		id = id.withRawTimestamp(timeToTimestamp(t, e, false))
+	}
+	return
+}
@@ -0,0 +1,59 @@
+package xtid
+
+import (
+	"io"
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// The sources set by SetSourceForType, replaced as a whole on every
+	// change so that generation reads them without locking
+	typeSources   atomic.Pointer[map[uint16]io.Reader]
+	typeSourcesMu sync.Mutex
+)
+
+// SetSourceForType sets the source of random bytes of the IDs of type typ,
+// overriding the package source set by SetSource for that type only, so that
+// e.g. session tokens can be minted from a hardware RNG while bulk IDs use
+// the fast default source. A nil src removes the override.
+//
+// The override applies to New, Make, MakeCompact and the generators without
+// a source of their own, see WithSource. It matches typ exactly, flag bits
+// included. Monotonic generators only read the source when a new tick starts,
+// so the IDs they mint within a tick share the source of the first one.
+// Like SetSource, it may be called concurrently with generation.
+func SetSourceForType(typ uint16, src io.Reader) {
+	typeSourcesMu.Lock()
+	defer typeSourcesMu.Unlock()
+
+	var m map[uint16]io.Reader
+	if old := typeSources.Load(); old != nil {
+		m = maps.Clone(*old)
+	}
+	if src == nil {
+		delete(m, typ)
+	} else {
+		if m == nil {
+			m = make(map[uint16]io.Reader)
+		}
+		m[typ] = src
+	}
+	if len(m) == 0 {
+		typeSources.Store(nil)
+		return
+	}
+	typeSources.Store(&m)
+}
+
+// loadSourceForType returns the source of random bytes of the IDs of type
+// typ: the one set by SetSourceForType, or else the package source.
+func loadSourceForType(typ uint16) io.Reader {
+	if m := typeSources.Load(); m != nil {
+		if src, ok := (*m)[typ]; ok {
+			return src
+		}
+	}
+	return loadSource()
+}
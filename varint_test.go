@@ -0,0 +1,56 @@
+package xtid
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVarint(t *testing.T) {
+	nanoGen, err := NewGenerator(WithNanoseconds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	release := now.Add(-24 * time.Hour)
+
+	for _, tt := range []struct {
+		name  string
+		codec VarintCodec
+		id    XTID
+	}{
+		{"micro", VarintCodec{}, Must(defaultGenerator.Make(now, 7))},
+		{"nano", VarintCodec{}, Must(nanoGen.Make(now, 7))},
+		{"large type", VarintCodec{}, Must(defaultGenerator.Make(now, 0xffff))},
+		{"codec epoch", NewVarintCodec(release), Must(defaultGenerator.Make(now, 7))},
+		{"codec epoch nano", NewVarintCodec(release), Must(nanoGen.Make(now, 7))},
+		// Timestamps before the codec epoch are negative
+		{"before codec epoch", NewVarintCodec(now), Must(defaultGenerator.Make(release, 7))},
+		{"nil", VarintCodec{}, Nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.codec.Append([]byte("prefix"), tt.id)[len("prefix"):]
+			got, n, err := tt.codec.Unmarshal(append(b, "trailer"...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.id || n != len(b) {
+				t.Errorf("Unmarshal = %s, %d, want %s, %d", got, n, tt.id, len(b))
+			}
+			for l := range len(b) {
+				if _, _, err := tt.codec.Unmarshal(b[:l]); !errors.Is(err, errVarint) {
+					t.Errorf("Unmarshal of %d bytes = %v, want %v", l, err, errVarint)
+				}
+			}
+		})
+	}
+
+	// Within a few years of the codec epoch, small types take up to 18 bytes
+	id := Must(defaultGenerator.Make(now, 7))
+	if b := NewVarintCodec(now.AddDate(-3, 0, 0)).Marshal(id); len(b) > 18 {
+		t.Errorf("encoding takes %d bytes, want at most 18", len(b))
+	}
+	if got, _, err := UnmarshalVarint(MarshalVarint(id)); err != nil || got != id {
+		t.Errorf("UnmarshalVarint = %s, %v, want %s", got, err, id)
+	}
+}
@@ -0,0 +1,118 @@
+//go:build ignore
+
+// This program generates vectors.json from the Go reference implementation.
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/it512/xtid"
+	"github.com/it512/xtid/vectors"
+)
+
+const base62Characters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// encode encodes b in base62 regardless of its validity as a XTID.
+func encode(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	s := n.Text(62)
+	// big.Int uses 0-9a-zA-Z, XTIDs 0-9A-Za-z
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return r
+	}, s)
+	return strings.Repeat("0", 27-len(s)) + s
+}
+
+func raw(ts uint64, typ uint16, payload string) []byte {
+	b := make([]byte, 20)
+	binary.BigEndian.PutUint64(b, ts)
+	binary.BigEndian.PutUint16(b[8:], typ)
+	p, err := hex.DecodeString(payload)
+	if err != nil || len(p) != 10 {
+		log.Fatalf("bad payload %q", payload)
+	}
+	copy(b[10:], p)
+	return b
+}
+
+func valid(name string, b []byte) vectors.Vector {
+	id, err := xtid.FromBytes(b)
+	if err != nil {
+		log.Fatalf("%s: %v", name, err)
+	}
+	if s := encode(b); s != id.String() {
+		log.Fatalf("%s: encoded %s, reference %s", name, s, id)
+	}
+	v := vectors.Vector{
+		Name:      name,
+		Hex:       hex.EncodeToString(b),
+		String:    id.String(),
+		Version:   uint8(id.Version()),
+		Timestamp: id.Timestamp(),
+		Type:      id.Type(),
+		Payload:   hex.EncodeToString(b[10:]),
+	}
+	// RFC 3339 only has 4-digit years, which the largest timestamps exceed
+	if t := id.Time().UTC(); id.Version().Known() && t.Year() >= 0 && t.Year() <= 9999 {
+		v.Time = t.Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+func main() {
+	xtid.SetEpoch(time.Time{})
+
+	t := time.Date(2024, 2, 29, 12, 34, 56, 789123456, time.UTC)
+	micro := uint64(t.UnixMicro())
+	nano := uint64(t.UnixNano()) | 1<<62
+
+	var set vectors.Set
+	set.Valid = []vectors.Vector{
+		valid("nil", make([]byte, 20)),
+		valid("max", xtid.Max.Bytes()),
+		valid("micro", raw(micro, 1, "0123456789abcdef0123")),
+		valid("nano", raw(nano, 1, "0123456789abcdef0123")),
+		valid("max type", raw(micro, 0xffff, "00000000000000000000")),
+		valid("max payload", raw(micro, 42, "ffffffffffffffffffff")),
+		valid("max micro timestamp", raw(1<<62-1, 7, "00000000000000000001")),
+		valid("min nano timestamp", raw(1<<62, 7, "00000000000000000001")),
+		valid("max nano timestamp", raw(1<<63-1, 7, "00000000000000000001")),
+		valid("unix epoch", raw(0, 3, "fedcba9876543210fedc")),
+		valid("unknown version", raw(2<<62|micro, 1, "0123456789abcdef0123")),
+	}
+
+	set.Invalid = []vectors.Invalid{
+		{Name: "empty", String: "", Reason: "length"},
+		{Name: "too short", String: set.Valid[2].String[1:], Reason: "length"},
+		{Name: "too long", String: set.Valid[2].String + "0", Reason: "length"},
+		{Name: "invalid character", String: "0000000000000000000000000-0", Reason: "character"},
+		{Name: "above max", String: "aWgEPTl1tmebfsQzFP4bxwgy80W", Reason: "range"},
+		{Name: "overflow", String: "zzzzzzzzzzzzzzzzzzzzzzzzzzz", Reason: "range"},
+	}
+	for _, v := range set.Invalid {
+		if _, err := xtid.Parse(v.String); err == nil {
+			log.Fatalf("%s: %q parses", v.Name, v.String)
+		}
+	}
+
+	b, err := json.MarshalIndent(set, "", "\t")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("vectors.json", append(b, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,142 @@
+// Package vectors holds the canonical XTID test vectors, generated from the
+// Go reference implementation, for validating implementations of XTIDs in
+// other languages. The vectors are also available as JSON, see JSON.
+package vectors
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/it512/xtid"
+)
+
+//go:generate go run gen.go
+
+//go:embed vectors.json
+var data []byte
+
+// A Vector is a valid XTID in its binary and string encodings, along with
+// its decoded fields.
+type Vector struct {
+	Name string `json:"name"`
+	// Hex is the 20-byte binary encoding, hex encoded.
+	Hex string `json:"hex"`
+	// String is the 27-character base62 encoding.
+	String    string `json:"string"`
+	Version   uint8  `json:"version"`
+	Timestamp uint64 `json:"timestamp"`
+	Type      uint16 `json:"type"`
+	// Payload is the 10-byte random payload, hex encoded.
+	Payload string `json:"payload"`
+	// Time is the time of the ID in RFC 3339 format, counting from the Unix
+	// epoch. It is empty for IDs of unknown versions, such as Max, and for
+	// those past the year 9999, which RFC 3339 can't represent: Timestamp
+	// holds their time.
+	Time string `json:"time,omitempty"`
+}
+
+// Bytes returns the binary encoding of the vector.
+func (v Vector) Bytes() []byte {
+	b, err := hex.DecodeString(v.Hex)
+	if err != nil {
+		panic(fmt.Sprintf("vectors: invalid vector %q: %v", v.Name, err))
+	}
+	return b
+}
+
+// An Invalid vector is a string which implementations must refuse to decode.
+type Invalid struct {
+	Name   string `json:"name"`
+	String string `json:"string"`
+	// Reason is one of "length", "character" or "range".
+	Reason string `json:"reason"`
+}
+
+// A Set is the complete set of test vectors.
+type Set struct {
+	Valid   []Vector  `json:"valid"`
+	Invalid []Invalid `json:"invalid"`
+}
+
+var set Set
+
+func init() {
+	if err := json.Unmarshal(data, &set); err != nil {
+		panic(fmt.Sprintf("vectors: invalid vectors.json: %v", err))
+	}
+}
+
+// JSON returns the test vectors in JSON format, as decoded into a Set.
+func JSON() []byte {
+	return bytes.Clone(data)
+}
+
+// All returns the test vectors.
+func All() Set {
+	return Set{
+		Valid:   append([]Vector(nil), set.Valid...),
+		Invalid: append([]Invalid(nil), set.Invalid...),
+	}
+}
+
+// A Codec converts XTIDs between their binary and string encodings.
+type Codec interface {
+	Encode(b []byte) (string, error)
+	Decode(s string) ([]byte, error)
+}
+
+type reference struct{}
+
+func (reference) Encode(b []byte) (string, error) {
+	id, err := xtid.FromBytes(b)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func (reference) Decode(s string) ([]byte, error) {
+	id, err := xtid.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return id.Bytes(), nil
+}
+
+// Reference is the Codec of the Go reference implementation.
+var Reference Codec = reference{}
+
+// Verify checks c against the test vectors: it must encode and decode every
+// valid vector, and refuse to decode every invalid one. All mismatches are
+// reported.
+func Verify(c Codec) error {
+	var errs []error
+	for _, v := range set.Valid {
+		b := v.Bytes()
+		s, err := c.Encode(b)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: encode: %w", v.Name, err))
+		case s != v.String:
+			errs = append(errs, fmt.Errorf("%s: encoded %q, want %q", v.Name, s, v.String))
+		}
+
+		d, err := c.Decode(v.String)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: decode: %w", v.Name, err))
+		case !bytes.Equal(d, b):
+			errs = append(errs, fmt.Errorf("%s: decoded %x, want %s", v.Name, d, v.Hex))
+		}
+	}
+	for _, v := range set.Invalid {
+		if _, err := c.Decode(v.String); err == nil {
+			errs = append(errs, fmt.Errorf("%s: decoded invalid %q", v.Name, v.String))
+		}
+	}
+	return errors.Join(errs...)
+}
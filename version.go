@@ -0,0 +1,57 @@
+package xtid
+
+import (
+	"fmt"
+)
+
+// Version identifies the layout of a XTID. It is stored in the top 2 bits of
+// the timestamp, which leaves the IDs minted before versions were introduced
+// at version 0, and lets the layout evolve without ambiguity in stored data.
+//
+// Parse, FromBytes and the other decoders accept IDs of any version, since
+// IDs stored before versions were introduced may have these bits set: those
+// that Make stamped with times before the epoch, whose timestamps wrapped
+// around, show as version 3, and keep their time. Known flags the IDs of other versions,
+// and ParseVersion rejects them.
+type Version uint8
+
+const (
+	// VersionMicro IDs have microsecond precision timestamps.
+	VersionMicro Version = versionMicro
+	// VersionNano IDs have nanosecond precision timestamps, see MakeNano.
+	VersionNano Version = versionNano
+)
+
+func (v Version) String() string {
+	switch v {
+	case VersionMicro:
+		return "micro"
+	case VersionNano:
+		return "nano"
+	default:
+		return fmt.Sprintf("Version(%d)", uint8(v))
+	}
+}
+
+// Known reports whether v is a layout version this package understands.
+func (v Version) Known() bool {
+	return v == VersionMicro || v == VersionNano
+}
+
+// Version returns the layout version of the ID.
+func (i XTID) Version() Version {
+	return Version(i.rawTimestamp() >> versionShift)
+}
+
+// ParseVersion decodes a string-encoded representation of a XTID like Parse,
+// additionally requiring it to have version v.
+func ParseVersion(s string, v Version) (XTID, error) {
+	id, err := Parse(s)
+	if err != nil {
+		return Nil, err
+	}
+	if id.Version() != v {
+		return Nil, fmt.Errorf("xtid: version %v, want %v", id.Version(), v)
+	}
+	return id, nil
+}
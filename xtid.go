@@ -3,11 +3,16 @@ package xtid
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/subtle"
 	"database/sql/driver"
+	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
+	"sync/atomic"
 	"time"
 )
 
@@ -30,20 +35,43 @@ const (
 	// The length of a XTID when string (base62) encoded
 	stringEncodedLength = 27
 
+	// The length of the bytea hex form of a XTID, as handed out by Postgres
+	// drivers: \x followed by 40 hex digits
+	byteaHexLength = 2 + 2*byteLength
+
 	// A string-encoded minimum value for a XTID
 	minStringEncoded = "000000000000000000000000000"
 
 	// A string-encoded maximum value for a XTID
 	maxStringEncoded = "aWgEPTl1tmebfsQzFP4bxwgy80V"
+
+	// The top bits of the timestamp hold the layout version of the ID, the
+	// remaining ones the time elapsed since the epoch.
+	versionBits   = 2
+	versionShift  = 64 - versionBits
+	timestampMask = 1<<versionShift - 1
+
+	// Microsecond timestamps, the original layout
+	versionMicro = 0
+	// Nanosecond timestamps
+	versionNano = 1
 )
 
+// PayloadLength is the length in bytes of the payload of a XTID, which
+// follows its timestamp and type and ends its binary form, see Payload.
+const PayloadLength = payloadLengthInBytes
+
 var (
-	source io.Reader = newEntropyPool()
+	// The package source of random bytes, see SetSource
+	source atomic.Pointer[io.Reader]
+
+	// The type of the IDs minted by New
+	defaultType uint16
 )
 
 // XTIDs are 20 bytes:
 //
-// 00-07 byte: uint64 timestamp
+// 00-07 byte: uint64 timestamp, whose top 2 bits hold the layout version
 // 08~11 byte: uint32 type
 // 12-19 byte: random "payload"
 type XTID [byteLength]byte
@@ -52,6 +80,7 @@ var (
 	errSize        = fmt.Errorf("Valid XTIDs are %v bytes", byteLength)
 	errStrSize     = fmt.Errorf("Valid encoded XTIDs are %v characters", stringEncodedLength)
 	errStrValue    = fmt.Errorf("Valid encoded XTIDs are bounded by %s and %s", minStringEncoded, maxStringEncoded)
+	errStrChar     = errors.New("Valid encoded XTIDs only contain the characters 0-9, A-Z and a-z")
 	errPayloadSize = fmt.Errorf("Valid XTID payloads are %v bytes", payloadLengthInBytes)
 
 	// Represents a completely empty (invalid) XTID
@@ -68,19 +97,81 @@ func (i XTID) Append(b []byte) []byte {
 
 // The timestamp portion of the ID as a Time object
 func (i XTID) Time() time.Time {
-	return correctedUTCTimestampToTime(i.Timestamp())
+	return correctedUTCTimestampToTime(i.rawTimestamp())
+}
+
+// Age returns the time elapsed since the timestamp of the ID, which is
+// negative for IDs minted in the future. Like Time, it assumes the ID counts
+// from the package epoch.
+func (i XTID) Age() time.Duration {
+	return time.Since(i.Time())
+}
+
+// IsOlderThan reports whether the ID was minted more than d ago, as used to
+// reject stale idempotency keys or to select IDs due for deletion.
+func (i XTID) IsOlderThan(d time.Duration) bool {
+	return i.Age() > d
+}
+
+// Precision returns the resolution of the timestamp of the ID: a
+// microsecond, or a nanosecond for IDs minted with MakeNano or a generator
+// using WithNanoseconds.
+func (i XTID) Precision() time.Duration {
+	if i.rawTimestamp()>>versionShift == versionNano {
+		return time.Nanosecond
+	}
+	return time.Microsecond
 }
 
 func (i XTID) Type() uint16 {
 	return binary.BigEndian.Uint16(i[timestampLengthInBytes:payloadStart])
 }
 
+// Payload returns the random portion of the ID.
+func (i XTID) Payload() (p [payloadLengthInBytes]byte) {
+	copy(p[:], i[payloadStart:])
+	return
+}
+
 // The timestamp portion of the ID as a bare integer which is uncorrected
-// for XTID's special epoch.
+// for XTID's special epoch, see SetEpoch. Its unit is given by Precision.
 func (i XTID) Timestamp() uint64 {
+	return i.rawTimestamp() & timestampMask
+}
+
+// micros returns the timestamp of the ID in microseconds since the epoch,
+// whatever its precision. Unlike Time().UnixMicro(), it can't overflow.
+func (i XTID) micros() int64 {
+	if i.rawTimestamp()>>versionShift == versionNano {
+		return int64(i.Timestamp() / 1e3)
+	}
+	return int64(i.Timestamp())
+}
+
+// The timestamp portion of the ID including the version bits
+func (i XTID) rawTimestamp() uint64 {
 	return binary.BigEndian.Uint64(i[:timestampLengthInBytes])
 }
 
+// A copy of the ID with the timestamp portion replaced
+func (i XTID) withRawTimestamp(ts uint64) XTID {
+	binary.BigEndian.PutUint64(i[:timestampLengthInBytes], ts)
+	return i
+}
+
+// WithType returns a copy of the ID with its type replaced by typ.
+func (i XTID) WithType(typ uint16) XTID {
+	binary.BigEndian.PutUint16(i[timestampLengthInBytes:payloadStart], typ)
+	return i
+}
+
+// WithTime returns a copy of the ID with its timestamp replaced by the one of
+// t, counting from the package epoch with the precision of the ID. The time
+// must not be before the epoch.
+func (i XTID) WithTime(t time.Time) XTID {
+	return i.withRawTimestamp(timeToTimestamp(t, epoch, i.Precision() == time.Nanosecond))
+}
+
 // String-encoded representation that can be passed through Parse()
 func (i XTID) String() string {
 	return string(i.Append(make([]byte, 0, stringEncodedLength)))
@@ -97,6 +188,13 @@ func (i XTID) IsNil() bool {
 	return i == Nil
 }
 
+// IsZero reports whether this is the Nil XTID, like IsNil. It lets fields
+// tagged omitzero with encoding/json, and libraries checking for an IsZero
+// method, omit Nil XTIDs.
+func (i XTID) IsZero() bool {
+	return i.IsNil()
+}
+
 // Get satisfies the flag.Getter interface, making it possible to use XTIDs as
 // part of of the command line options of a program.
 func (i XTID) Get() any {
@@ -144,6 +242,15 @@ func (i *XTID) scan(b []byte) error {
 		return i.UnmarshalBinary(b)
 	case stringEncodedLength:
 		return i.UnmarshalText(b)
+	case byteaHexLength:
+		if b[0] != '\\' || b[1] != 'x' {
+			return errSize
+		}
+		var raw [byteLength]byte
+		if _, err := hex.Decode(raw[:], b[2:]); err != nil {
+			return err
+		}
+		return i.UnmarshalBinary(raw[:])
 	default:
 		return errSize
 	}
@@ -167,6 +274,35 @@ func (i *XTID) UnmarshalJSON(v []byte) error {
 	return i.scan(v)
 }
 
+// ScanRedis implements the Scanner interface of go-redis, used for the XTID
+// fields of structs filled by HGETALL. It accepts both the binary values
+// go-redis writes for XTID arguments, through MarshalBinary, and
+// string-encoded ones.
+func (i *XTID) ScanRedis(s string) error {
+	return i.scan([]byte(s))
+}
+
+// EncodeSpanner implements the spanner.Encoder interface, storing the XTID
+// in a STRING(27) column, or as NULL for Nil. See package xtidspanner for
+// BYTES(20) columns.
+func (i XTID) EncodeSpanner() (any, error) {
+	return i.Value()
+}
+
+// DecodeSpanner implements the spanner.Decoder interface. It accepts the
+// values of STRING(27) and BYTES(20) columns, the latter either raw or
+// base64 encoded, as Spanner hands them out, and NULL, which is Nil.
+func (i *XTID) DecodeSpanner(input any) error {
+	if s, ok := input.(string); ok && len(s) == base64.StdEncoding.EncodedLen(byteLength) {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		return i.scan(b)
+	}
+	return i.Scan(input)
+}
+
 // Value converts the XTID into a SQL driver value which can be used to
 // directly use the XTID as parameter to a SQL query.
 func (i XTID) Value() (driver.Value, error) {
@@ -178,7 +314,9 @@ func (i XTID) Value() (driver.Value, error) {
 
 // Scan implements the sql.Scanner interface. It supports converting from
 // string, []byte, or nil into a XTID value. Attempting to convert from
-// another type will return an error.
+// another type will return an error. Besides the binary and string forms,
+// it accepts the \x-prefixed hex form some drivers return for bytea
+// columns.
 func (i *XTID) Scan(src any) error {
 	switch v := src.(type) {
 	case nil:
@@ -194,20 +332,21 @@ func (i *XTID) Scan(src any) error {
 
 // Parse decodes a string-encoded representation of a XTID object
 func Parse(s string) (XTID, error) {
+	id, err := parse(s)
+	if err != nil {
+		notifyParseError(s, err)
+	}
+	return id, err
+}
+
+func parse(s string) (id XTID, err error) {
 	if len(s) != stringEncodedLength {
 		return Nil, errStrSize
 	}
-
-	src := [stringEncodedLength]byte{}
-	dst := [byteLength]byte{}
-
-	copy(src[:], s[:])
-
-	if err := fastDecodeBase62(dst[:], src[:]); err != nil {
-		return Nil, errStrValue
+	if err := fastDecodeBase62(id[:], s); err != nil {
+		return Nil, err
 	}
-
-	return FromBytes(dst[:])
+	return id, nil
 }
 
 // Parse decodes a string-encoded representation of a XTID object.
@@ -220,12 +359,61 @@ func ParseOrNil(s string) XTID {
 	return id
 }
 
+// ParseError reports the failure to parse an item of a list of XTIDs.
+type ParseError struct {
+	Index int
+	Input string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("xtid: item %d: %q: %v", e.Index, e.Input, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseAll parses every string of ss. It returns the IDs, with Nil in place
+// of the ones which failed to parse, and when any did, the errors, one per
+// string, which are nil for valid ones and *ParseError otherwise.
+func ParseAll(ss []string) ([]XTID, []error) {
+	ids := make([]XTID, len(ss))
+	var errs []error
+	for j, s := range ss {
+		id, err := Parse(s)
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(ss))
+			}
+			errs[j] = &ParseError{Index: j, Input: s, Err: err}
+			continue
+		}
+		ids[j] = id
+	}
+	return ids, errs
+}
+
+// ParseAllStrict parses every string of ss, stopping at the first which
+// fails to parse, reported as *ParseError.
+func ParseAllStrict(ss []string) ([]XTID, error) {
+	ids := make([]XTID, len(ss))
+	for j, s := range ss {
+		id, err := Parse(s)
+		if err != nil {
+			return nil, &ParseError{Index: j, Input: s, Err: err}
+		}
+		ids[j] = id
+	}
+	return ids, nil
+}
+
 func timeToCorrectedUTCTimestamp(t time.Time) uint64 {
-	return uint64(t.UnixMicro())
+	return timeToTimestamp(t, epoch, false)
 }
 
 func correctedUTCTimestampToTime(ts uint64) time.Time {
-	return time.UnixMicro(int64(ts))
+	return timestampToTime(ts, epoch)
 }
 
 func Must(id XTID, err error) XTID {
@@ -235,32 +423,97 @@ func Must(id XTID, err error) XTID {
 	return id
 }
 
+// MustParse is like Parse, but panics if s is not a valid XTID. It eases the
+// declaration of IDs in package variables and test fixtures.
+func MustParse(s string) XTID {
+	id, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("xtid: Parse(%q): %v", s, err))
+	}
+	return id
+}
+
+// MustNew makes a new XTID of type typ like NewWithType, but panics on
+// failure.
+func MustNew(typ uint16) XTID {
+	return Must(NewWithType(typ))
+}
+
+// MustFromBytes is like FromBytes, but panics if b is not a valid XTID.
+func MustFromBytes(b []byte) XTID {
+	id, err := FromBytes(b)
+	if err != nil {
+		panic(fmt.Sprintf("xtid: FromBytes(%x): %v", b, err))
+	}
+	return id
+}
+
+// NewOrNil makes a new XTID of the default type, or returns Nil if it fails.
+// Use New to find out why.
 func NewOrNil() (id XTID) {
-	id, _ = NewWithType(0)
+	id, _ = New()
 	return
 }
 
+// New makes a new XTID of the default type, see SetDefaultType.
+func New() (XTID, error) {
+	return NewWithType(defaultType)
+}
+
+// SetDefaultType sets the type of the XTIDs minted by New and NewOrNil, 0
+// unless set. Like SetSource, it should be called once at startup.
+func SetDefaultType(typ uint16) {
+	defaultType = typ
+}
+
+// DefaultType returns the type set with SetDefaultType.
+func DefaultType() uint16 {
+	return defaultType
+}
+
 func NewWithType(typ uint16) (id XTID, err error) {
 	id, err = Make(time.Now(), typ)
 	return
 }
 
+// NewBatch makes n new XTIDs of type typ stamped with the current time,
+// reading their random bytes at once, see Generator.NewBatch.
+func NewBatch(typ uint16, n int) ([]XTID, error) {
+	return defaultGenerator.NewBatch(typ, n)
+}
+
 // Make a new XTID using custome time and type
 func Make(t time.Time, typ uint16) (id XTID, err error) {
-	_, err = io.ReadFull(source, id[payloadStart:])
+	return defaultGenerator.Make(t, typ)
+}
 
-	if err != nil {
-		id = Nil // don't leak random bytes on error
-		return
-	}
+// MakeNano makes a new XTID using custom time and type, with a nanosecond
+// precision timestamp. Such IDs keep events happening within the same
+// microsecond ordered, but always sort after microsecond precision ones.
+func MakeNano(t time.Time, typ uint16) (id XTID, err error) {
+	return nanoGenerator.Make(t, typ)
+}
 
-	ts := timeToCorrectedUTCTimestamp(t)
-	binary.BigEndian.PutUint64(id[:timestampLengthInBytes], ts)
+// MakeWithPayload makes a XTID using custom time and type, and payload in
+// place of random bytes, for deterministic IDs built from external entropy
+// or hashes. Unlike Make, it can't fail. The time must not be before the
+// epoch.
+func MakeWithPayload(t time.Time, typ uint16, payload [payloadLengthInBytes]byte) (id XTID) {
+	id = id.withRawTimestamp(timeToCorrectedUTCTimestamp(t))
 	binary.BigEndian.PutUint16(id[timestampLengthInBytes:payloadStart], typ)
-
+	copy(id[payloadStart:], payload[:])
 	return
 }
 
+// MakeWithPayloadBytes is like MakeWithPayload, taking the payload as a
+// slice, which must be 10 bytes long.
+func MakeWithPayloadBytes(t time.Time, typ uint16, payload []byte) (XTID, error) {
+	if len(payload) != payloadLengthInBytes {
+		return Nil, errPayloadSize
+	}
+	return MakeWithPayload(t, typ, [payloadLengthInBytes]byte(payload)), nil
+}
+
 // Constructs a XTID from a 20-byte binary representation
 func FromBytes(b []byte) (XTID, error) {
 	var id XTID
@@ -283,16 +536,25 @@ func FromBytesOrNil(b []byte) XTID {
 	return id
 }
 
-// Sets the global source of random bytes for XTID generation. This
-// should probably only be set once globally. While this is technically
-// thread-safe as in it won't cause corruption, there's no guarantee
-// on ordering.
+// Sets the global source of random bytes for XTID generation, used by
+// New, Make and the generators without a source of their own, see
+// WithSource. It may be called at any time, concurrently with generation:
+// the IDs minted while it runs use either the old or the new source, and the
+// old source may still be read after SetSource returns, so it must stay
+// usable until the calls in flight complete. A nil src restores crypto/rand
+// without buffering. On TinyGo and WebAssembly, the default source is
+// crypto/rand without buffering; wrap host entropy in an EntropyFunc to use
+// it instead.
 func SetSource(src io.Reader) {
 	if src == nil {
-		source = rand.Reader
-		return
+		src = rand.Reader
 	}
-	source = src
+	source.Store(&src)
+}
+
+// loadSource returns the global source of random bytes.
+func loadSource() io.Reader {
+	return *source.Load()
 }
 
 // Implements comparison for XTID type
@@ -300,9 +562,26 @@ func Compare(a, b XTID) int {
 	return bytes.Compare(a[:], b[:])
 }
 
+// EqualConstantTime reports whether a and b are equal in time independent of
+// their contents, for XTIDs serving as secrets such as password reset or
+// invite tokens, where == would leak how much of a guess was right.
+func EqualConstantTime(a, b XTID) bool {
+	return subtle.ConstantTimeCompare(a[:], b[:]) == 1
+}
+
+// IDGen returns a function minting XTIDs of type typ, which returns Nil when
+// minting fails. See IDGenErr for a variant reporting the error.
 func IDGen(typ uint16) func() XTID {
 	return func() (id XTID) {
 		id, _ = NewWithType(typ)
 		return
 	}
 }
+
+// IDGenErr returns a function minting XTIDs of type typ. Failures to read
+// from the source of random bytes are reported as *EntropyError.
+func IDGenErr(typ uint16) func() (XTID, error) {
+	return func() (XTID, error) {
+		return NewWithType(typ)
+	}
+}
@@ -0,0 +1,151 @@
+// Package xtidaudit checks the integrity of sets of XTIDs, such as exports of
+// an event store: it detects invalid and duplicate IDs and IDs with out of
+// range timestamps, and counts the IDs of each type.
+//
+// Detecting duplicates takes memory proportional to the number of IDs, unless
+// the IDs are fed in sorted order and Sorted is set, in which case it takes
+// none: audit large datasets in key order, or partition them by time or
+// type.
+package xtidaudit
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// DefaultSamples is the number of samples kept for each kind of problem
+// when Auditor.Samples is zero.
+const DefaultSamples = 10
+
+// An Auditor accumulates the findings on the IDs it is fed. The zero value
+// is ready to use, and checks neither bound of the timestamps.
+type Auditor struct {
+	// Sorted tells the IDs are fed in sorted order, in which case
+	// duplicates are detected without memory, and IDs out of order are
+	// reported.
+	Sorted bool
+	// Min and Max bound the times of the IDs, when not zero.
+	Min, Max time.Time
+	// Samples is the number of samples kept for each kind of problem,
+	// DefaultSamples when zero.
+	Samples int
+
+	seen    map[xtid.XTID]struct{}
+	last    xtid.XTID
+	hasLast bool
+	report  Report
+}
+
+// A Report is the outcome of an audit.
+type Report struct {
+	Total      int64
+	Invalid    int64
+	Duplicates int64
+	OutOfRange int64
+	// Unordered counts the IDs smaller than the one before them, in sorted
+	// mode only.
+	Unordered int64
+	// Types counts the valid IDs of each type.
+	Types map[uint16]int64
+	// Oldest and Newest are the times of the oldest and newest valid IDs.
+	Oldest, Newest time.Time
+
+	// Samples of the problems found, the input for invalid IDs
+	InvalidSamples    []string
+	DuplicateSamples  []xtid.XTID
+	OutOfRangeSamples []xtid.XTID
+}
+
+// OK reports whether no problem was found.
+func (r *Report) OK() bool {
+	return r.Invalid == 0 && r.Duplicates == 0 && r.OutOfRange == 0 && r.Unordered == 0
+}
+
+// SortedTypes returns the types found, in increasing order.
+func (r *Report) SortedTypes() []uint16 {
+	return slices.Sorted(maps.Keys(r.Types))
+}
+
+func (a *Auditor) samples() int {
+	if a.Samples > 0 {
+		return a.Samples
+	}
+	return DefaultSamples
+}
+
+// AddString parses s and audits the resulting ID, or records it as invalid.
+func (a *Auditor) AddString(s string) {
+	id, err := xtid.Parse(s)
+	if err != nil {
+		a.report.Total++
+		a.report.Invalid++
+		if len(a.report.InvalidSamples) < a.samples() {
+			a.report.InvalidSamples = append(a.report.InvalidSamples, s)
+		}
+		return
+	}
+	a.Add(id)
+}
+
+// Add audits id.
+func (a *Auditor) Add(id xtid.XTID) {
+	r := &a.report
+	r.Total++
+
+	dup := false
+	if a.Sorted {
+		if a.hasLast {
+			switch c := xtid.Compare(id, a.last); {
+			case c == 0:
+				dup = true
+			case c < 0:
+				r.Unordered++
+			}
+		}
+		a.last, a.hasLast = id, true
+	} else {
+		if a.seen == nil {
+			a.seen = make(map[xtid.XTID]struct{})
+		}
+		_, dup = a.seen[id]
+		a.seen[id] = struct{}{}
+	}
+	if dup {
+		r.Duplicates++
+		if len(r.DuplicateSamples) < a.samples() {
+			r.DuplicateSamples = append(r.DuplicateSamples, id)
+		}
+	}
+
+	t := id.Time()
+	if (!a.Min.IsZero() && t.Before(a.Min)) || (!a.Max.IsZero() && t.After(a.Max)) {
+		r.OutOfRange++
+		if len(r.OutOfRangeSamples) < a.samples() {
+			r.OutOfRangeSamples = append(r.OutOfRangeSamples, id)
+		}
+	}
+	if r.Oldest.IsZero() || t.Before(r.Oldest) {
+		r.Oldest = t
+	}
+	if t.After(r.Newest) {
+		r.Newest = t
+	}
+
+	if r.Types == nil {
+		r.Types = make(map[uint16]int64)
+	}
+	r.Types[id.Type()]++
+}
+
+// Report returns the findings so far.
+func (a *Auditor) Report() Report {
+	r := a.report
+	r.Types = maps.Clone(r.Types)
+	r.InvalidSamples = slices.Clone(r.InvalidSamples)
+	r.DuplicateSamples = slices.Clone(r.DuplicateSamples)
+	r.OutOfRangeSamples = slices.Clone(r.OutOfRangeSamples)
+	return r
+}
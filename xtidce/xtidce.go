@@ -0,0 +1,86 @@
+// Package xtidce standardizes CloudEvents "id" attributes on XTIDs.
+//
+// The helpers work on any value exposing the CloudEvents id and time
+// attributes, such as *event.Event from the CloudEvents Go SDK.
+package xtidce
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// Event is the subset of a CloudEvent used by this package.
+type Event interface {
+	ID() string
+	SetID(string)
+	Time() time.Time
+	SetTime(time.Time)
+}
+
+// SetID mints a new XTID of type typ, stamped with the event time when it is
+// set, and stores it as the id attribute of e.
+func SetID(e Event, typ uint16) (xtid.XTID, error) {
+	t := e.Time()
+	if t.IsZero() {
+		t = time.Now()
+	}
+	id, err := xtid.Make(t, typ)
+	if err != nil {
+		return xtid.Nil, err
+	}
+	e.SetID(id.String())
+	return id, nil
+}
+
+// ID parses the id attribute of e as a XTID.
+func ID(e Event) (xtid.XTID, error) {
+	id, err := xtid.Parse(e.ID())
+	if err != nil {
+		return xtid.Nil, fmt.Errorf("cloudevent id %q: %w", e.ID(), err)
+	}
+	return id, nil
+}
+
+// Validate checks that the id attribute of e is a XTID.
+func Validate(e Event) error {
+	_, err := ID(e)
+	return err
+}
+
+// ValidateType checks that the id attribute of e is a XTID of type typ.
+func ValidateType(e Event, typ uint16) error {
+	id, err := ID(e)
+	if err != nil {
+		return err
+	}
+	if id.Type() != typ {
+		return fmt.Errorf("cloudevent id %q: type %d, want %d", e.ID(), id.Type(), typ)
+	}
+	return nil
+}
+
+// Time returns the time attribute of e, falling back on the timestamp
+// embedded in its XTID id when the attribute is missing.
+func Time(e Event) (time.Time, error) {
+	if t := e.Time(); !t.IsZero() {
+		return t, nil
+	}
+	id, err := ID(e)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return id.Time(), nil
+}
+
+// FillTime sets the time attribute of e from its XTID id when the attribute
+// is missing.
+func FillTime(e Event) error {
+	t, err := Time(e)
+	if err != nil {
+		return err
+	}
+	e.SetTime(t)
+	return nil
+}
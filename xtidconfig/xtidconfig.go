@@ -0,0 +1,106 @@
+// Package xtidconfig helps populating XTID fields of configuration structs
+// from environment variables and configuration files.
+//
+// DecodeHook is a mapstructure decode hook, as used by viper:
+//
+//	viper.Unmarshal(&cfg, viper.DecodeHook(xtidconfig.DecodeHook()))
+//
+// ID satisfies envconfig's Decoder interface. Note that xtid.XTID fields
+// are supported by envconfig as is, through their Set method.
+//
+// Neither library is a dependency of this package: the hook has the
+// signature of mapstructure.DecodeHookFuncType, and ID the Decode method of
+// envconfig.Decoder.
+package xtidconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/it512/xtid"
+)
+
+var (
+	idType    = reflect.TypeOf(xtid.Nil)
+	idPtrType = reflect.TypeOf(&xtid.XTID{})
+	idsType   = reflect.TypeOf([]xtid.XTID(nil))
+)
+
+// DecodeHook returns a mapstructure decode hook converting strings to XTIDs,
+// and comma-separated strings to lists of XTIDs. Empty strings decode to
+// xtid.Nil, or to an empty list.
+func DecodeHook() func(from, to reflect.Type, data any) (any, error) {
+	return func(from, to reflect.Type, data any) (any, error) {
+		var s string
+		switch v := data.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		default:
+			return data, nil
+		}
+
+		switch to {
+		case idType:
+			return parse(s)
+		case idPtrType:
+			id, err := parse(s)
+			return &id, err
+		case idsType:
+			return parseList(s)
+		}
+		return data, nil
+	}
+}
+
+func parse(s string) (xtid.XTID, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return xtid.Nil, nil
+	}
+	id, err := xtid.Parse(s)
+	if err != nil {
+		return xtid.Nil, fmt.Errorf("xtidconfig: invalid XTID %q: %w", s, err)
+	}
+	return id, nil
+}
+
+func parseList(s string) ([]xtid.XTID, error) {
+	if strings.TrimSpace(s) == "" {
+		return []xtid.XTID{}, nil
+	}
+	parts := strings.Split(s, ",")
+	ids := make([]xtid.XTID, len(parts))
+	for j, p := range parts {
+		id, err := parse(p)
+		if err != nil {
+			return nil, err
+		}
+		ids[j] = id
+	}
+	return ids, nil
+}
+
+// ID is a XTID satisfying envconfig's Decoder interface.
+type ID xtid.XTID
+
+// Decode parses value into the ID. An empty value decodes to xtid.Nil.
+func (i *ID) Decode(value string) error {
+	id, err := parse(value)
+	if err != nil {
+		return err
+	}
+	*i = ID(id)
+	return nil
+}
+
+// XTID returns the ID as a xtid.XTID.
+func (i ID) XTID() xtid.XTID {
+	return xtid.XTID(i)
+}
+
+func (i ID) String() string {
+	return xtid.XTID(i).String()
+}
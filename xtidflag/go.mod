@@ -0,0 +1,13 @@
+module github.com/it512/xtid/xtidflag
+
+go 1.23
+
+require (
+	github.com/it512/xtid v0.0.0-00010101000000-000000000000
+	github.com/spf13/cobra v1.8.1
+	github.com/spf13/pflag v1.0.5
+)
+
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
+
+replace github.com/it512/xtid => ../
@@ -0,0 +1,189 @@
+// Package xtidflag provides pflag values holding XTIDs, for cobra and pflag
+// based command line tools, along with completion hints for cobra. It is a
+// module of its own, so that only its users depend on them.
+//
+//	var id xtid.XTID
+//	var ids []xtid.XTID
+//	xtidflag.IDVar(cmd.Flags(), &id, "id", "the ID to look up")
+//	xtidflag.IDSliceVar(cmd.Flags(), &ids, "ids", "the IDs to look up")
+//	xtidflag.RegisterCompletion(cmd, "id")
+package xtidflag
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+
+	"github.com/it512/xtid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Value is a pflag.Value holding a XTID.
+type Value struct {
+	p *xtid.XTID
+}
+
+// NewValue returns a Value storing the ID into p.
+func NewValue(p *xtid.XTID) *Value {
+	return &Value{p: p}
+}
+
+func (v *Value) String() string {
+	if v.p == nil || v.p.IsNil() {
+		return ""
+	}
+	return v.p.String()
+}
+
+func (v *Value) Set(s string) error {
+	id, err := xtid.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	*v.p = id
+	return nil
+}
+
+func (v *Value) Type() string {
+	return "xtid"
+}
+
+// SliceValue is a pflag.Value holding a list of XTIDs, which accepts repeated
+// flags as well as comma-separated values. It implements pflag.SliceValue.
+type SliceValue struct {
+	p       *[]xtid.XTID
+	changed bool
+}
+
+// NewSliceValue returns a SliceValue storing the IDs into p. The IDs in p
+// are the default ones, replaced by the first use of the flag.
+func NewSliceValue(p *[]xtid.XTID) *SliceValue {
+	return &SliceValue{p: p}
+}
+
+func (v *SliceValue) String() string {
+	if v.p == nil {
+		return "[]"
+	}
+	return "[" + strings.Join(toStrings(*v.p), ",") + "]"
+}
+
+func (v *SliceValue) Set(s string) error {
+	ids, err := parseCSV(s)
+	if err != nil {
+		return err
+	}
+	if !v.changed {
+		*v.p = ids
+		v.changed = true
+	} else {
+		*v.p = append(*v.p, ids...)
+	}
+	return nil
+}
+
+func (v *SliceValue) Type() string {
+	return "xtidSlice"
+}
+
+// Append adds the ID encoded in s to the list.
+func (v *SliceValue) Append(s string) error {
+	id, err := xtid.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	*v.p = append(*v.p, id)
+	return nil
+}
+
+// Replace replaces the list with the IDs encoded in ss.
+func (v *SliceValue) Replace(ss []string) error {
+	ids := make([]xtid.XTID, len(ss))
+	for j, s := range ss {
+		id, err := xtid.Parse(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		ids[j] = id
+	}
+	*v.p = ids
+	return nil
+}
+
+// GetSlice returns the string encodings of the IDs of the list.
+func (v *SliceValue) GetSlice() []string {
+	return toStrings(*v.p)
+}
+
+func toStrings(ids []xtid.XTID) []string {
+	ss := make([]string, len(ids))
+	for j, id := range ids {
+		ss[j] = id.String()
+	}
+	return ss
+}
+
+func parseCSV(s string) ([]xtid.XTID, error) {
+	if s == "" {
+		return nil, nil
+	}
+	ss, err := csv.NewReader(bytes.NewBufferString(s)).Read()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]xtid.XTID, len(ss))
+	for j, s := range ss {
+		id, err := xtid.Parse(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		ids[j] = id
+	}
+	return ids, nil
+}
+
+// IDVar defines a XTID flag with the specified name and usage.
+func IDVar(fs *pflag.FlagSet, p *xtid.XTID, name, usage string) {
+	fs.Var(NewValue(p), name, usage)
+}
+
+// IDVarP is like IDVar, but accepts a shorthand letter.
+func IDVarP(fs *pflag.FlagSet, p *xtid.XTID, name, shorthand, usage string) {
+	fs.VarP(NewValue(p), name, shorthand, usage)
+}
+
+// IDSliceVar defines a XTID list flag with the specified name and usage.
+func IDSliceVar(fs *pflag.FlagSet, p *[]xtid.XTID, name, usage string) {
+	fs.Var(NewSliceValue(p), name, usage)
+}
+
+// IDSliceVarP is like IDSliceVar, but accepts a shorthand letter.
+func IDSliceVarP(fs *pflag.FlagSet, p *[]xtid.XTID, name, shorthand, usage string) {
+	fs.VarP(NewSliceValue(p), name, shorthand, usage)
+}
+
+// Completion is a cobra completion function for XTID flags and arguments. As
+// IDs can't be guessed, it disables file completion and, for shells
+// supporting it, shows a hint on the expected format.
+func Completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	last := toComplete
+	if i := strings.LastIndexByte(last, ','); i >= 0 {
+		last = last[i+1:]
+	}
+	if _, err := xtid.Parse(last); err == nil {
+		return []string{toComplete}, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cobra.AppendActiveHelp(nil, "Expecting a 27-character XTID"), cobra.ShellCompDirectiveNoFileComp
+}
+
+// RegisterCompletion registers Completion for the flags of cmd with the
+// given names.
+func RegisterCompletion(cmd *cobra.Command, names ...string) error {
+	for _, name := range names {
+		if err := cmd.RegisterFlagCompletionFunc(name, Completion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
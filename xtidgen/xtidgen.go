@@ -0,0 +1,222 @@
+// Package xtidgen generates Go code declaring XTID types from a manifest
+// shared between services, so that they agree on the numbers of the types.
+//
+// A manifest is a YAML or JSON document listing the types:
+//
+//	package: model
+//	types:
+//	  - name: user
+//	    code: 1
+//	  - name: order_line
+//	    code: 2
+//	    const: TypeLine
+//
+// The generated file declares a named type, one constant per type,
+// TypeUser and TypeLine above, a String method, and registers the names in
+// xtid.DefaultRegistry on initialization. It is usually produced by the
+// gentypes command of cmd/xtid from a go:generate directive:
+//
+//	//go:generate go run github.com/it512/xtid/cmd/xtid@latest gentypes -o types_gen.go types.yaml
+//
+// Like cmd/xtid and xtidtemporal, it is a module of its own, so that the
+// users of xtid don't depend on its YAML parser.
+package xtidgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTypeName is the name of the generated type when the manifest
+// doesn't give one.
+const DefaultTypeName = "Type"
+
+// A Manifest lists XTID types.
+type Manifest struct {
+	// Package is the name of the package of the generated code.
+	Package string `yaml:"package" json:"package"`
+	// TypeName is the name of the generated type, DefaultTypeName when
+	// empty. It prefixes the names of the constants.
+	TypeName string `yaml:"type" json:"type"`
+	// Owner, when set, is the owner of the range reserved for the types,
+	// which are then registered with RegisterFor, see xtid.Registry.Reserve.
+	Owner string `yaml:"owner" json:"owner"`
+	// Types lists the types.
+	Types []Type `yaml:"types" json:"types"`
+}
+
+// A Type is an entry of a manifest.
+type Type struct {
+	// Name is the name registered for the type.
+	Name string `yaml:"name" json:"name"`
+	// Code is the number of the type.
+	Code uint16 `yaml:"code" json:"code"`
+	// Const is the name of its constant, derived from Name when empty.
+	Const string `yaml:"const" json:"const"`
+	// Doc is the doc comment of its constant.
+	Doc string `yaml:"doc" json:"doc"`
+}
+
+// Parse reads a manifest from YAML or JSON. It is validated by Generate.
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("xtidgen: %w", err)
+	}
+	return &m, nil
+}
+
+func (m *Manifest) typeName() string {
+	if m.TypeName != "" {
+		return m.TypeName
+	}
+	return DefaultTypeName
+}
+
+func (m *Manifest) constName(t Type) string {
+	if t.Const != "" {
+		return t.Const
+	}
+	var b strings.Builder
+	b.WriteString(m.typeName())
+	for _, w := range strings.FieldsFunc(t.Name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+// Validate checks that the identifiers are valid, and that no two types
+// share a name, a code or a constant. The constants must not collide with
+// the other identifiers of the generated file either: the type, its
+// <TypeName>Of function, and the imported packages.
+func (m *Manifest) Validate() error {
+	if !token.IsIdentifier(m.Package) {
+		return fmt.Errorf("xtidgen: invalid package name %q", m.Package)
+	}
+	if !token.IsIdentifier(m.typeName()) {
+		return fmt.Errorf("xtidgen: invalid type name %q", m.typeName())
+	}
+	names := make(map[string]bool)
+	codes := make(map[uint16]string)
+	// The identifiers declared or imported by the generated file
+	reserved := map[string]bool{
+		m.typeName():        true,
+		m.typeName() + "Of": true,
+		"xtid":              true,
+		"strconv":           true,
+	}
+	if m.typeName() == "xtid" || m.typeName() == "strconv" {
+		return fmt.Errorf("xtidgen: type name %q collides with an imported package", m.typeName())
+	}
+	consts := make(map[string]bool)
+	for _, t := range m.Types {
+		if t.Name == "" {
+			return fmt.Errorf("xtidgen: type %d has no name", t.Code)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("xtidgen: type name %q is listed twice", t.Name)
+		}
+		if old, ok := codes[t.Code]; ok {
+			return fmt.Errorf("xtidgen: types %q and %q share code %d", old, t.Name, t.Code)
+		}
+		c := m.constName(t)
+		if !token.IsIdentifier(c) || consts[c] {
+			return fmt.Errorf("xtidgen: invalid or duplicate constant name %q for type %q", c, t.Name)
+		}
+		if reserved[c] {
+			return fmt.Errorf("xtidgen: constant name %q for type %q is reserved by the generated code", c, t.Name)
+		}
+		names[t.Name], codes[t.Code], consts[c] = true, t.Name, true
+	}
+	return nil
+}
+
+var fileTemplate = template.Must(template.New("").Parse(`// Code generated by xtid gentypes{{if .Source}} from {{.Source}}{{end}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"strconv"
+
+	"github.com/it512/xtid"
+)
+
+// {{.TypeName}} is the type of an XTID.
+type {{.TypeName}} uint16
+
+const (
+{{- range .Types}}
+{{- if .Doc}}
+	// {{.Doc}}
+{{- end}}
+	{{.Const}} {{$.TypeName}} = {{.Code}}
+{{- end}}
+)
+
+func init() {
+{{- range .Types}}
+{{- if $.Owner}}
+	xtid.DefaultRegistry.MustRegisterFor({{printf "%q" $.Owner}}, uint16({{.Const}}), {{printf "%q" .Name}})
+{{- else}}
+	xtid.DefaultRegistry.MustRegister(uint16({{.Const}}), {{printf "%q" .Name}})
+{{- end}}
+{{- end}}
+}
+
+// String returns the name of t.
+func (t {{.TypeName}}) String() string {
+	switch t {
+{{- range .Types}}
+	case {{.Const}}:
+		return {{printf "%q" .Name}}
+{{- end}}
+	}
+	return "{{.TypeName}}(" + strconv.Itoa(int(t)) + ")"
+}
+
+// New makes a new XTID of type t.
+func (t {{.TypeName}}) New() (xtid.XTID, error) {
+	return xtid.NewWithType(uint16(t))
+}
+
+// {{.TypeName}}Of returns the type of id.
+func {{.TypeName}}Of(id xtid.XTID) {{.TypeName}} {
+	return {{.TypeName}}(id.Type())
+}
+`))
+
+// Generate returns the gofmt-ed source of the Go file declaring the types
+// of m, mentioning source as its origin when not empty.
+func Generate(m *Manifest, source string) ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	types := slices.Clone(m.Types)
+	for j := range types {
+		types[j].Const = m.constName(types[j])
+		types[j].Doc = strings.Join(strings.Fields(types[j].Doc), " ")
+	}
+	slices.SortFunc(types, func(a, b Type) int { return int(a.Code) - int(b.Code) })
+
+	var buf bytes.Buffer
+	err := fileTemplate.Execute(&buf, struct {
+		Source, Package, TypeName, Owner string
+		Types                            []Type
+	}{source, m.Package, m.typeName(), m.Owner, types})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
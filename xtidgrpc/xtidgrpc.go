@@ -0,0 +1,104 @@
+// Package xtidgrpc provides gRPC interceptors that propagate a request XTID
+// between services through call metadata.
+//
+// It is a module of its own, so that only its users depend on gRPC.
+package xtidgrpc
+
+import (
+	"context"
+
+	"github.com/it512/xtid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataKey is the metadata key carrying the request ID.
+const MetadataKey = "x-request-id"
+
+// NewContext returns a copy of ctx carrying the request ID id. It is the
+// same as xtid.NewIDContext.
+func NewContext(ctx context.Context, id xtid.XTID) context.Context {
+	return xtid.NewIDContext(ctx, id)
+}
+
+// FromContext returns the request ID stored in ctx, if any. It is the same
+// as xtid.IDFromContext.
+func FromContext(ctx context.Context) (xtid.XTID, bool) {
+	return xtid.IDFromContext(ctx)
+}
+
+// outgoing attaches the request ID of ctx to the outgoing metadata, minting
+// a new one when ctx carries none.
+func outgoing(ctx context.Context, gen func() xtid.XTID) context.Context {
+	id, ok := FromContext(ctx)
+	if !ok || id.IsNil() {
+		if id = gen(); id.IsNil() {
+			return ctx
+		}
+		ctx = NewContext(ctx, id)
+	}
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, id.String())
+}
+
+// incoming extracts the request ID from the incoming metadata of ctx,
+// minting a new one when it is absent or invalid.
+func incoming(ctx context.Context, gen func() xtid.XTID) context.Context {
+	var id xtid.XTID
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(MetadataKey); len(v) > 0 {
+			id = xtid.ParseOrNil(v[0])
+		}
+	}
+	if id.IsNil() {
+		if id = gen(); id.IsNil() {
+			return ctx
+		}
+	}
+	return NewContext(ctx, id)
+}
+
+// UnaryClientInterceptor returns a client interceptor attaching the request
+// ID found in the call context, or a new one of type typ, to every call.
+func UnaryClientInterceptor(typ uint16) grpc.UnaryClientInterceptor {
+	gen := xtid.IDGen(typ)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoing(ctx, gen), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of
+// UnaryClientInterceptor.
+func StreamClientInterceptor(typ uint16) grpc.StreamClientInterceptor {
+	gen := xtid.IDGen(typ)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoing(ctx, gen), desc, cc, method, opts...)
+	}
+}
+
+// UnaryServerInterceptor returns a server interceptor that extracts the
+// request ID from the incoming metadata, or mints one of type typ, and makes
+// it available to the handler through FromContext.
+func UnaryServerInterceptor(typ uint16) grpc.UnaryServerInterceptor {
+	gen := xtid.IDGen(typ)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(incoming(ctx, gen), req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func StreamServerInterceptor(typ uint16) grpc.StreamServerInterceptor {
+	gen := xtid.IDGen(typ)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ss, incoming(ss.Context(), gen)})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,53 @@
+// Package xtidhttp provides net/http middleware that tags every request with
+// a XTID.
+package xtidhttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/it512/xtid"
+)
+
+// Header is the HTTP header used to propagate and echo request IDs.
+const Header = "X-Request-Id"
+
+// RequestID is a middleware that assigns a XTID of type 0 to every request.
+// See RequestIDWithType.
+func RequestID(next http.Handler) http.Handler {
+	return RequestIDWithType(0)(next)
+}
+
+// RequestIDWithType returns a middleware that assigns a XTID to every request.
+// A valid XTID found in the incoming Header is propagated as is, otherwise a
+// new one of the given type is generated. The ID is stored in the request
+// context, where it can be retrieved with FromContext, and echoed in the
+// response Header.
+func RequestIDWithType(typ uint16) func(http.Handler) http.Handler {
+	gen := xtid.IDGen(typ)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := xtid.ParseOrNil(r.Header.Get(Header))
+			if id.IsNil() {
+				id = gen()
+			}
+			if !id.IsNil() {
+				w.Header().Set(Header, id.String())
+				r = r.WithContext(NewContext(r.Context(), id))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NewContext returns a copy of ctx carrying the request ID id. It is the
+// same as xtid.NewIDContext.
+func NewContext(ctx context.Context, id xtid.XTID) context.Context {
+	return xtid.NewIDContext(ctx, id)
+}
+
+// FromContext returns the request ID stored in ctx, if any. It is the same
+// as xtid.IDFromContext.
+func FromContext(ctx context.Context) (xtid.XTID, bool) {
+	return xtid.IDFromContext(ctx)
+}
@@ -0,0 +1,59 @@
+package xtididem
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// Number of claims between two sweeps of the expired ones
+const sweepInterval = 1024
+
+// A MemoryStore is a Store keeping the claims in memory, for single
+// instance services and tests.
+type MemoryStore struct {
+	mu     sync.Mutex
+	claims map[xtid.XTID]time.Time
+	n      int
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{claims: make(map[xtid.XTID]time.Time)}
+}
+
+func (s *MemoryStore) Claim(ctx context.Context, key xtid.XTID, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.n++; s.n%sweepInterval == 0 {
+		for k, exp := range s.claims {
+			if !now.Before(exp) {
+				delete(s.claims, k)
+			}
+		}
+	}
+	if exp, ok := s.claims[key]; ok && now.Before(exp) {
+		return false, nil
+	}
+	s.claims[key] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key xtid.XTID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.claims, key)
+	return nil
+}
+
+// Len returns the number of claims held, including expired ones not swept
+// yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.claims)
+}
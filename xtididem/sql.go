@@ -0,0 +1,66 @@
+package xtididem
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// A SQLStore is a Store keeping the claims in a Postgres table, see
+// CreateTable. Expired claims are overwritten when claimed again, and can
+// be purged with Purge.
+type SQLStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLStore returns a SQLStore using table, optionally schema qualified.
+func NewSQLStore(db *sql.DB, table string) *SQLStore {
+	return &SQLStore{db: db, table: quoteIdent(table)}
+}
+
+// CreateTable returns the statement creating the table of s.
+func (s *SQLStore) CreateTable() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (key char(27) PRIMARY KEY, expires_at timestamptz NOT NULL)", s.table)
+}
+
+func (s *SQLStore) Claim(ctx context.Context, key xtid.XTID, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	// Take over expired claims, but not live ones
+	res, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		"INSERT INTO %[1]s (key, expires_at) VALUES ($1, $2) "+
+			"ON CONFLICT (key) DO UPDATE SET expires_at = EXCLUDED.expires_at WHERE %[1]s.expires_at <= $3",
+		s.table), key.String(), now.Add(ttl), now)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (s *SQLStore) Release(ctx context.Context, key xtid.XTID) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = $1", s.table), key.String())
+	return err
+}
+
+// Purge deletes the expired claims, and returns their number.
+func (s *SQLStore) Purge(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE expires_at <= $1", s.table), time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// quoteIdent quotes a possibly schema qualified identifier.
+func quoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for j, p := range parts {
+		parts[j] = `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
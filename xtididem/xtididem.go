@@ -0,0 +1,129 @@
+// Package xtididem implements idempotency keys on XTIDs: clients mint an
+// XTID for every operation they may retry, and servers perform each
+// operation once per key.
+//
+// A Validator checks the keys clients send, rejecting those too old to be
+// remembered thanks to the timestamp they carry, and a Store records the
+// keys claimed by operations. Guard ties them together:
+//
+//	g := &xtididem.Guard{
+//		Validator: xtididem.Validator{MaxAge: 24 * time.Hour, Types: []uint16{TypeRequest}},
+//		Store:     xtididem.NewSQLStore(db, "idempotency_keys"),
+//	}
+//	key, err := g.Claim(ctx, r.Header.Get("Idempotency-Key"))
+//	if errors.Is(err, xtididem.ErrDuplicate) {
+//		// replay the response, or report a conflict
+//	}
+//	if err := process(); err != nil {
+//		g.Store.Release(ctx, key) // let the client retry
+//	}
+//
+// Keys only need remembering for as long as the Validator accepts them, as
+// older ones are rejected before reaching the Store.
+package xtididem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+var (
+	// ErrInvalid is returned for keys which are not valid XTIDs, or are
+	// of a type not accepted.
+	ErrInvalid = errors.New("xtididem: invalid idempotency key")
+	// ErrStale is returned for keys older than the freshness window.
+	ErrStale = errors.New("xtididem: stale idempotency key")
+	// ErrFuture is returned for keys minted too far in the future.
+	ErrFuture = errors.New("xtididem: idempotency key from the future")
+	// ErrDuplicate is returned by Guard.Claim for keys already claimed.
+	ErrDuplicate = errors.New("xtididem: idempotency key already claimed")
+)
+
+// DefaultMaxSkew is the tolerance for keys minted in the future, by clients
+// whose clocks are ahead, when Validator.MaxSkew is zero.
+const DefaultMaxSkew = time.Minute
+
+// A Validator checks client-supplied idempotency keys.
+type Validator struct {
+	// MaxAge is the freshness window: keys older than MaxAge are rejected.
+	// It is required.
+	MaxAge time.Duration
+	// MaxSkew is how far in the future keys may be, DefaultMaxSkew when
+	// zero.
+	MaxSkew time.Duration
+	// Types lists the types of the accepted keys, any when empty.
+	Types []uint16
+	// Now returns the current time, time.Now when nil.
+	Now func() time.Time
+}
+
+func (v *Validator) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+// Validate parses key and checks its type and age.
+func (v *Validator) Validate(key string) (xtid.XTID, error) {
+	id, err := xtid.Parse(key)
+	if err != nil || id.IsNil() {
+		return xtid.Nil, fmt.Errorf("%w: %q", ErrInvalid, key)
+	}
+	if len(v.Types) > 0 && !slices.Contains(v.Types, id.Type()) {
+		return xtid.Nil, fmt.Errorf("%w: type %d not accepted", ErrInvalid, id.Type())
+	}
+	skew := v.MaxSkew
+	if skew <= 0 {
+		skew = DefaultMaxSkew
+	}
+	now, t := v.now(), id.Time()
+	switch {
+	case t.Before(now.Add(-v.MaxAge)):
+		return xtid.Nil, fmt.Errorf("%w: minted %v ago", ErrStale, now.Sub(t).Round(time.Second))
+	case t.After(now.Add(skew)):
+		return xtid.Nil, fmt.Errorf("%w: minted %v ahead", ErrFuture, t.Sub(now).Round(time.Second))
+	}
+	return id, nil
+}
+
+// A Store records claimed idempotency keys. Implementations must be safe
+// for concurrent use, and Claim must be atomic: of concurrent claims of the
+// same key, only one succeeds.
+type Store interface {
+	// Claim claims key until it expires after ttl, and reports whether it
+	// did, that is whether key wasn't already claimed.
+	Claim(ctx context.Context, key xtid.XTID, ttl time.Duration) (bool, error)
+	// Release drops the claim on key, so that it can be claimed again.
+	Release(ctx context.Context, key xtid.XTID) error
+}
+
+// A Guard validates idempotency keys and claims them in a Store.
+type Guard struct {
+	Validator Validator
+	Store     Store
+}
+
+// Claim validates key and claims it for as long as it is fresh. It fails
+// with ErrDuplicate when the key is already claimed.
+func (g *Guard) Claim(ctx context.Context, key string) (xtid.XTID, error) {
+	id, err := g.Validator.Validate(key)
+	if err != nil {
+		return xtid.Nil, err
+	}
+	// The claim must outlive the window in which the key is accepted
+	ttl := id.Time().Add(g.Validator.MaxAge).Sub(g.Validator.now())
+	ok, err := g.Store.Claim(ctx, id, max(ttl, time.Second))
+	if err != nil {
+		return xtid.Nil, err
+	}
+	if !ok {
+		return id, ErrDuplicate
+	}
+	return id, nil
+}
@@ -0,0 +1,57 @@
+// Package xtidkafka helps using XTIDs as Kafka message keys.
+//
+// The helpers here have no dependency on a particular client: KeyEncoder
+// satisfies sarama's Encoder interface, and Key/FromKey produce and consume
+// the raw []byte keys used by franz-go's kgo.Record.
+package xtidkafka
+
+import (
+	"hash/fnv"
+
+	"github.com/it512/xtid"
+)
+
+// PartitionKey returns a partition key derived from the payload of id only.
+//
+// Unlike the full binary form, whose leading bytes are the timestamp, the
+// payload is uniformly distributed, so IDs minted in the same instant do not
+// pile up on the same partition under range or prefix based partitioners.
+func PartitionKey(id xtid.XTID) []byte {
+	b := id.Bytes()
+	return b[len(b)-xtid.PayloadLength:]
+}
+
+// Partition maps id onto one of numPartitions partitions by hashing its
+// payload. The mapping is stable for a given id and partition count.
+func Partition(id xtid.XTID, numPartitions int32) int32 {
+	if numPartitions <= 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write(PartitionKey(id))
+	return int32(h.Sum32() % uint32(numPartitions))
+}
+
+// Key returns the 20-byte binary form of id, for use as a message key.
+func Key(id xtid.XTID) []byte {
+	return id.Bytes()
+}
+
+// FromKey decodes a message key produced by Key or KeyEncoder.
+func FromKey(b []byte) (xtid.XTID, error) {
+	return xtid.FromBytes(b)
+}
+
+// KeyEncoder encodes a XTID as a binary message key. It implements the
+// sarama.Encoder interface.
+type KeyEncoder xtid.XTID
+
+// Encode returns the 20-byte binary form of the ID.
+func (k KeyEncoder) Encode() ([]byte, error) {
+	return xtid.XTID(k).MarshalBinary()
+}
+
+// Length returns the length of the encoded key.
+func (k KeyEncoder) Length() int {
+	return len(k)
+}
@@ -0,0 +1,109 @@
+// Package xtidkv builds the keys of embedded ordered key-value stores, such
+// as Badger, Bolt or Pebble, from XTIDs.
+//
+// Keys made by Key are a prefix, naming a table or index, followed by the
+// binary form of an ID. They sort like the IDs, that is by time, and Range
+// gives the bounds of the keys of the IDs minted within a time range:
+//
+//	start, end := xtidkv.Range([]byte("events/"), since, until)
+//	it := db.NewIter(&pebble.IterOptions{LowerBound: start, UpperBound: end})
+//
+// Keys made by TypeKey put the type of the ID before it, so that the IDs of
+// each type are scanned by time on their own with TypeRange.
+//
+// Range bounds assume IDs of microsecond precision, the default; IDs of
+// nanosecond precision sort after all of them.
+package xtidkv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// Length of binary XTIDs
+const byteLength = 20
+
+// ErrKey is returned when parsing a key which wasn't built with the given
+// prefix.
+var ErrKey = errors.New("xtidkv: key doesn't match the prefix")
+
+// Key returns prefix followed by the binary form of id.
+func Key(prefix []byte, id xtid.XTID) []byte {
+	return AppendKey(make([]byte, 0, len(prefix)+byteLength), prefix, id)
+}
+
+// AppendKey appends the key of id under prefix to dst, see Key.
+func AppendKey(dst, prefix []byte, id xtid.XTID) []byte {
+	dst = append(dst, prefix...)
+	return append(dst, id[:]...)
+}
+
+// ParseKey returns the ID of a key built by Key with prefix.
+func ParseKey(prefix, key []byte) (xtid.XTID, error) {
+	b, ok := bytes.CutPrefix(key, prefix)
+	if !ok || len(b) != byteLength {
+		return xtid.Nil, ErrKey
+	}
+	return xtid.FromBytes(b)
+}
+
+// Range returns the bounds of the keys built by Key with prefix for the IDs
+// minted from lo included to hi excluded.
+func Range(prefix []byte, lo, hi time.Time) (start, end []byte) {
+	return Key(prefix, xtid.LowerBound(lo)), Key(prefix, xtid.LowerBound(hi))
+}
+
+// TypeKey returns prefix followed by the type of id, big-endian, and the
+// binary form of id.
+func TypeKey(prefix []byte, id xtid.XTID) []byte {
+	return AppendKey(TypePrefix(prefix, id.Type()), nil, id)
+}
+
+// TypePrefix returns the prefix shared by the keys built by TypeKey with
+// prefix for the IDs of type typ.
+func TypePrefix(prefix []byte, typ uint16) []byte {
+	p := make([]byte, len(prefix), len(prefix)+2+byteLength)
+	copy(p, prefix)
+	return binary.BigEndian.AppendUint16(p, typ)
+}
+
+// ParseTypeKey returns the ID of a key built by TypeKey with prefix.
+func ParseTypeKey(prefix, key []byte) (xtid.XTID, error) {
+	b, ok := bytes.CutPrefix(key, prefix)
+	if !ok || len(b) != 2+byteLength {
+		return xtid.Nil, ErrKey
+	}
+	id, err := xtid.FromBytes(b[2:])
+	if err != nil {
+		return xtid.Nil, err
+	}
+	if id.Type() != binary.BigEndian.Uint16(b) {
+		return xtid.Nil, ErrKey
+	}
+	return id, nil
+}
+
+// TypeRange returns the bounds of the keys built by TypeKey with prefix for
+// the IDs of type typ minted from lo included to hi excluded.
+func TypeRange(prefix []byte, typ uint16, lo, hi time.Time) (start, end []byte) {
+	p := TypePrefix(prefix, typ)
+	return Key(p, xtid.LowerBound(lo)), Key(p, xtid.LowerBound(hi))
+}
+
+// PrefixEnd returns the smallest key greater than all the keys starting
+// with prefix, the exclusive upper bound of a prefix scan, or nil when there
+// is none, that is when prefix is empty or made of 0xff bytes.
+func PrefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for j := len(end) - 1; j >= 0; j-- {
+		if end[j] != 0xff {
+			end[j]++
+			return end[:j+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+// Package xtidmigrate rewrites the UUID or serial key column of a Postgres
+// table to XTIDs.
+//
+// The migration runs in two steps. Run adds a column holding the new IDs and
+// fills it in batches, recording every old key and its new ID in a mapping
+// table, which can be joined to rewrite the foreign keys pointing at the
+// table. Once they are, SwapStatements gives the statements making the new
+// column the key of the table, to be reviewed and run by hand.
+//
+// IDs take the time of the created_at column of their row when there is one,
+// so that they sort like the rows were created, and are then derived from
+// the old keys with xtid.ImportForeign. Otherwise they are minted with a
+// monotonic generator in the order of the old keys, which keeps the order of
+// serial keys.
+package xtidmigrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// DefaultBatchSize is the number of rows migrated per transaction when the
+// Plan doesn't say.
+const DefaultBatchSize = 1000
+
+// A Plan describes the migration of a table.
+type Plan struct {
+	// Table is the name of the table, optionally schema qualified.
+	Table string
+	// Key is the name of its UUID or serial key column.
+	Key string
+	// CreatedAt is the name of its creation time column, if any.
+	CreatedAt string
+	// Type is the type of the new IDs.
+	Type uint16
+	// Column is the name of the column holding the new IDs, Key with an
+	// "_xtid" suffix when empty.
+	Column string
+	// Mapping is the name of the mapping table, Table with an "_xtid_map"
+	// suffix when empty. It has an old_key text column and a new_id one.
+	Mapping string
+	// BatchSize is the number of rows migrated per transaction,
+	// DefaultBatchSize when zero.
+	BatchSize int
+}
+
+func (p Plan) column() string {
+	if p.Column != "" {
+		return p.Column
+	}
+	return p.Key + "_xtid"
+}
+
+func (p Plan) mapping() string {
+	if p.Mapping != "" {
+		return p.Mapping
+	}
+	return p.Table + "_xtid_map"
+}
+
+func (p Plan) batchSize() int {
+	if p.BatchSize > 0 {
+		return p.BatchSize
+	}
+	return DefaultBatchSize
+}
+
+func (p Plan) validate() error {
+	if p.Table == "" || p.Key == "" {
+		return errors.New("xtidmigrate: the table and key column are required")
+	}
+	return nil
+}
+
+// Stats reports the progress of a migration.
+type Stats struct {
+	// Rows is the number of rows migrated.
+	Rows int64
+	// Batches is the number of transactions committed.
+	Batches int64
+}
+
+// Run migrates the rows of the table which don't have a new ID yet. It can
+// be interrupted and run again, and progress, when not nil, is called after
+// every batch.
+func Run(ctx context.Context, db *sql.DB, p Plan, progress func(Stats)) (Stats, error) {
+	var stats Stats
+	if err := p.validate(); err != nil {
+		return stats, err
+	}
+	for _, stmt := range setupStatements(p) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return stats, fmt.Errorf("xtidmigrate: %s: %w", stmt, err)
+		}
+	}
+
+	keyType, err := columnType(ctx, db, p.Table, p.Key)
+	if err != nil {
+		return stats, err
+	}
+	gen, err := xtid.NewGenerator(xtid.WithMonotonic())
+	if err != nil {
+		return stats, err
+	}
+	for {
+		n, err := migrateBatch(ctx, db, p, keyType, gen)
+		if err != nil {
+			return stats, err
+		}
+		if n == 0 {
+			return stats, nil
+		}
+		stats.Rows += int64(n)
+		stats.Batches++
+		if progress != nil {
+			progress(stats)
+		}
+	}
+}
+
+func setupStatements(p Plan) []string {
+	return []string{
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s char(27) UNIQUE",
+			quoteIdent(p.Table), quoteIdent(p.column())),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (old_key text PRIMARY KEY, new_id char(27) NOT NULL UNIQUE)",
+			quoteIdent(p.mapping())),
+	}
+}
+
+// columnType returns the SQL type of column col of table, so that keys read
+// as text can be cast back to it and compared with the column as is, which
+// keeps its index usable.
+func columnType(ctx context.Context, db *sql.DB, table, col string) (string, error) {
+	var typ string
+	err := db.QueryRowContext(ctx,
+		"SELECT format_type(atttypid, atttypmod) FROM pg_attribute WHERE attrelid = $1::regclass AND attname = $2 AND NOT attisdropped",
+		quoteIdent(table), col).Scan(&typ)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("xtidmigrate: no column %s in %s", col, table)
+	}
+	return typ, err
+}
+
+// selectStatement returns the query locking the next batch of rows to
+// migrate. The keys are sorted on the key column itself, qualified so that
+// it isn't taken for the text output column, which would sort serial keys
+// as strings, 10 before 2.
+func selectStatement(p Plan, createdAt string) string {
+	table := quoteIdent(p.Table)
+	return fmt.Sprintf(
+		"SELECT %[1]s.%[2]s::text AS old_key, %[3]s FROM %[1]s WHERE %[1]s.%[4]s IS NULL ORDER BY %[1]s.%[2]s LIMIT %[5]d FOR UPDATE",
+		table, quoteIdent(p.Key), createdAt, quoteIdent(p.column()), p.batchSize())
+}
+
+type row struct {
+	key       string
+	createdAt sql.NullTime
+}
+
+func migrateBatch(ctx context.Context, db *sql.DB, p Plan, keyType string, gen *xtid.Generator) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	createdAt := "NULL::timestamptz"
+	if p.CreatedAt != "" {
+		createdAt = quoteIdent(p.CreatedAt)
+	}
+	rows, err := tx.QueryContext(ctx, selectStatement(p, createdAt))
+	if err != nil {
+		return 0, err
+	}
+	var batch []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.key, &r.createdAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, len(batch))
+	ids := make([]string, len(batch))
+	now := time.Now()
+	for j, r := range batch {
+		var id xtid.XTID
+		if r.createdAt.Valid {
+			id = xtid.ImportForeign(r.createdAt.Time, p.Type, []byte(r.key))
+		} else if id, err = gen.Make(now, p.Type); err != nil {
+			return 0, fmt.Errorf("xtidmigrate: key %s: %w", r.key, err)
+		}
+		keys[j], ids[j] = r.key, id.String()
+	}
+
+	// The keys are cast to the type of the key column rather than the other
+	// way around, so that the rows are found through its index
+	update := fmt.Sprintf(
+		"UPDATE %[1]s SET %[2]s = v.new_id FROM unnest($1::text[], $2::text[]) AS v(old_key, new_id) WHERE %[1]s.%[3]s = v.old_key::%[4]s",
+		quoteIdent(p.Table), quoteIdent(p.column()), quoteIdent(p.Key), keyType)
+	insert := fmt.Sprintf(
+		"INSERT INTO %s (old_key, new_id) SELECT * FROM unnest($1::text[], $2::text[])",
+		quoteIdent(p.mapping()))
+	for _, stmt := range []string{update, insert} {
+		if _, err := tx.ExecContext(ctx, stmt, textArray(keys), textArray(ids)); err != nil {
+			return 0, err
+		}
+	}
+	return len(batch), tx.Commit()
+}
+
+// textArray returns the literal of the Postgres text array of elems, which
+// any driver can bind as a string.
+func textArray(elems []string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for j, e := range elems {
+		if j > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('"')
+		for _, c := range []byte(e) {
+			if c == '"' || c == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		}
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// SwapStatements returns the statements making the column of new IDs the
+// key of the table, keeping the old key column renamed with an "_old"
+// suffix. They assume the primary key constraint has the default name, and
+// must be run once the foreign keys pointing at the table are rewritten.
+func SwapStatements(p Plan) []string {
+	table, key, col := quoteIdent(p.Table), quoteIdent(p.Key), quoteIdent(p.column())
+	name := p.Table
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return []string{
+		fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, quoteIdent(name+"_pkey")),
+		fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, key, quoteIdent(p.Key+"_old")),
+		fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, col, key),
+		fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", table, key),
+		fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s)", table, key),
+	}
+}
+
+// quoteIdent quotes a possibly schema qualified identifier.
+func quoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for j, p := range parts {
+		parts[j] = `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,170 @@
+package xtidmigrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeDB stands in for a Postgres table of serial keys, answering the
+// statements of Run. It sorts the keys like Postgres would for the ORDER BY
+// clause it is given: by value on the key column, as text otherwise.
+type fakeDB struct {
+	orderBy  string
+	limit    int
+	keys     []int
+	ids      map[int]string
+	assigned []int
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if !strings.HasPrefix(s.query, "UPDATE") {
+		return driver.RowsAffected(0), nil
+	}
+	keys, ids := parseArray(args[0].(string)), parseArray(args[1].(string))
+	for j, k := range keys {
+		n, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, err
+		}
+		s.db.ids[n] = ids[j]
+		s.db.assigned = append(s.db.assigned, n)
+	}
+	return driver.RowsAffected(len(keys)), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "format_type") {
+		return &fakeRows{cols: []string{"format_type"}, vals: [][]driver.Value{{"bigint"}}}, nil
+	}
+	keys := slices.Clone(s.db.keys)
+	if strings.Contains(s.query, "ORDER BY "+s.db.orderBy+" ") {
+		slices.Sort(keys)
+	} else {
+		slices.SortFunc(keys, func(a, b int) int {
+			return strings.Compare(strconv.Itoa(a), strconv.Itoa(b))
+		})
+	}
+	r := &fakeRows{cols: []string{"old_key", "created_at"}}
+	for _, k := range keys {
+		if _, ok := s.db.ids[k]; !ok && len(r.vals) < s.db.limit {
+			r.vals = append(r.vals, []driver.Value{strconv.Itoa(k), nil})
+		}
+	}
+	return r, nil
+}
+
+type fakeRows struct {
+	cols []string
+	vals [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.vals) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.vals[0])
+	r.vals = r.vals[1:]
+	return nil
+}
+
+// parseArray parses the text arrays of textArray, whose elements here hold
+// no quotes.
+func parseArray(s string) []string {
+	s = strings.Trim(s, "{}")
+	if s == "" {
+		return nil
+	}
+	elems := strings.Split(s, ",")
+	for j, e := range elems {
+		elems[j] = strings.Trim(e, `"`)
+	}
+	return elems
+}
+
+func TestRunKeepsSerialKeyOrder(t *testing.T) {
+	fake := &fakeDB{
+		orderBy: `"items"."id"`,
+		limit:   5,
+		ids:     make(map[int]string),
+	}
+	for k := 12; k >= 1; k-- {
+		fake.keys = append(fake.keys, k)
+	}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	stats, err := Run(context.Background(), db, Plan{Table: "items", Key: "id", BatchSize: 5}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Rows != 12 || stats.Batches != 3 {
+		t.Errorf("stats = %+v, want 12 rows in 3 batches", stats)
+	}
+	if want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}; !slices.Equal(fake.assigned, want) {
+		t.Fatalf("keys migrated in order %v, want %v", fake.assigned, want)
+	}
+	for k := 2; k <= 12; k++ {
+		if fake.ids[k-1] >= fake.ids[k] {
+			t.Errorf("ID of key %d %s not after the one of key %d %s", k, fake.ids[k], k-1, fake.ids[k-1])
+		}
+	}
+}
+
+func TestTextArray(t *testing.T) {
+	for _, tt := range []struct {
+		elems []string
+		want  string
+	}{
+		{nil, "{}"},
+		{[]string{"1", "2"}, `{"1","2"}`},
+		{[]string{`a"b`, `c\d`, "e,f"}, `{"a\"b","c\\d","e,f"}`},
+	} {
+		if got := textArray(tt.elems); got != tt.want {
+			t.Errorf("textArray(%q) = %s, want %s", tt.elems, got, tt.want)
+		}
+	}
+}
+
+func ExampleSwapStatements() {
+	for _, stmt := range SwapStatements(Plan{Table: "public.items", Key: "id"}) {
+		fmt.Println(stmt)
+	}
+	// Output:
+	// ALTER TABLE "public"."items" DROP CONSTRAINT "items_pkey"
+	// ALTER TABLE "public"."items" RENAME COLUMN "id" TO "id_old"
+	// ALTER TABLE "public"."items" RENAME COLUMN "id_xtid" TO "id"
+	// ALTER TABLE "public"."items" ALTER COLUMN "id" SET NOT NULL
+	// ALTER TABLE "public"."items" ADD PRIMARY KEY ("id")
+}
@@ -0,0 +1,5 @@
+module github.com/it512/xtid/xtidnode
+
+go 1.23
+
+require golang.org/x/sys v0.28.0
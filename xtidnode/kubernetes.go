@@ -0,0 +1,262 @@
+package xtidnode
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount/"
+
+// microTime is the layout of the Kubernetes MicroTime type.
+const microTime = "2006-01-02T15:04:05.000000Z07:00"
+
+// Kubernetes is a Backend claiming node IDs with coordination.k8s.io/v1
+// Lease objects named Prefix-<node> in Namespace, talking to the API server
+// directly over HTTP.
+type Kubernetes struct {
+	// Client performs the requests, it must authenticate to the API server.
+	Client *http.Client
+	// Host is the base URL of the API server.
+	Host string
+	// Token is the bearer token sent with every request, if any.
+	Token string
+
+	Namespace string
+	Prefix    string
+	// Identity is recorded as the lease holder, typically the pod name,
+	// followed by a random suffix unique to the claim, so that two claims
+	// of the same process or pod never share a lease.
+	Identity string
+	// Duration after which an unrenewed lease can be taken over.
+	Duration time.Duration
+
+	mu      sync.Mutex
+	holders map[uint64]string
+}
+
+// NewKubernetesInCluster returns a Kubernetes backend configured from the
+// service account mounted in the current pod.
+func NewKubernetesInCluster(namespace, prefix, identity string, duration time.Duration) (*Kubernetes, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("xtidnode: not running in a Kubernetes cluster")
+	}
+	token, err := os.ReadFile(serviceAccountDir + "token")
+	if err != nil {
+		return nil, err
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("xtidnode: invalid service account CA certificate")
+	}
+	return &Kubernetes{
+		Client: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+			Timeout:   10 * time.Second,
+		},
+		Host:      "https://" + net.JoinHostPort(host, port),
+		Token:     string(bytes.TrimSpace(token)),
+		Namespace: namespace,
+		Prefix:    prefix,
+		Identity:  identity,
+		Duration:  duration,
+	}, nil
+}
+
+type lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   leaseMetadata `json:"metadata"`
+	Spec       leaseSpec     `json:"spec"`
+}
+
+type leaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+}
+
+func (l *lease) expired(now time.Time) bool {
+	t, err := time.Parse(microTime, l.Spec.RenewTime)
+	if err != nil {
+		return true
+	}
+	return now.After(t.Add(time.Duration(l.Spec.LeaseDurationSeconds) * time.Second))
+}
+
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("xtidnode: kubernetes API returned status %d", int(e))
+}
+
+func (b *Kubernetes) name(node uint64) string {
+	return fmt.Sprintf("%s-%d", b.Prefix, node)
+}
+
+func (b *Kubernetes) url(name string) string {
+	u := fmt.Sprintf("%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", b.Host, b.Namespace)
+	if name != "" {
+		u += "/" + name
+	}
+	return u
+}
+
+func (b *Kubernetes) do(ctx context.Context, method, url string, in, out any) error {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if b.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.Token)
+	}
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return statusError(resp.StatusCode)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+func (b *Kubernetes) hold(l *lease, holder string, now time.Time) {
+	l.Spec.HolderIdentity = holder
+	l.Spec.LeaseDurationSeconds = int(b.Duration / time.Second)
+	l.Spec.AcquireTime = now.UTC().Format(microTime)
+	l.Spec.RenewTime = l.Spec.AcquireTime
+}
+
+func (b *Kubernetes) Claim(ctx context.Context, max uint64) (uint64, error) {
+	n, err := nonce()
+	if err != nil {
+		return 0, err
+	}
+	holder := b.Identity + "/" + n
+
+	for node := uint64(0); node < max; node++ {
+		now := time.Now()
+		l := lease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata:   leaseMetadata{Name: b.name(node), Namespace: b.Namespace},
+		}
+		b.hold(&l, holder, now)
+
+		err := b.do(ctx, http.MethodPost, b.url(""), &l, nil)
+		if err == nil {
+			return b.claimed(node, holder), nil
+		}
+		if err != statusError(http.StatusConflict) {
+			return 0, err
+		}
+
+		// The lease exists, take it over if it was abandoned, even by this
+		// process: a live lease may back another generator
+		var cur lease
+		if err := b.do(ctx, http.MethodGet, b.url(l.Metadata.Name), nil, &cur); err != nil {
+			return 0, err
+		}
+		if !cur.expired(now) {
+			continue
+		}
+		b.hold(&cur, holder, now)
+		err = b.do(ctx, http.MethodPut, b.url(l.Metadata.Name), &cur, nil)
+		if err == nil {
+			return b.claimed(node, holder), nil
+		}
+		if err != statusError(http.StatusConflict) {
+			return 0, err
+		}
+	}
+	return 0, ErrExhausted
+}
+
+// claimed records holder as ours for node, and returns node.
+func (b *Kubernetes) claimed(node uint64, holder string) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.holders == nil {
+		b.holders = make(map[uint64]string)
+	}
+	b.holders[node] = holder
+	return node
+}
+
+// get returns the lease of node, failing unless we still hold it.
+func (b *Kubernetes) get(ctx context.Context, node uint64) (*lease, error) {
+	b.mu.Lock()
+	holder, ok := b.holders[node]
+	b.mu.Unlock()
+	if !ok {
+		return nil, errNotClaimed
+	}
+
+	var l lease
+	if err := b.do(ctx, http.MethodGet, b.url(b.name(node)), nil, &l); err != nil {
+		return nil, err
+	}
+	if l.Spec.HolderIdentity != holder {
+		return nil, fmt.Errorf("xtidnode: lease %s is held by %q", l.Metadata.Name, l.Spec.HolderIdentity)
+	}
+	return &l, nil
+}
+
+func (b *Kubernetes) Renew(ctx context.Context, node uint64) error {
+	l, err := b.get(ctx, node)
+	if err != nil {
+		return err
+	}
+	l.Spec.RenewTime = time.Now().UTC().Format(microTime)
+	return b.do(ctx, http.MethodPut, b.url(l.Metadata.Name), l, nil)
+}
+
+func (b *Kubernetes) Release(ctx context.Context, node uint64) error {
+	l, err := b.get(ctx, node)
+	b.mu.Lock()
+	delete(b.holders, node)
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	opts := map[string]any{
+		"apiVersion":    "v1",
+		"kind":          "DeleteOptions",
+		"preconditions": map[string]string{"resourceVersion": l.Metadata.ResourceVersion},
+	}
+	return b.do(ctx, http.MethodDelete, b.url(l.Metadata.Name), opts, nil)
+}
@@ -0,0 +1,130 @@
+package xtidnode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLeases serves the Lease objects of a namespace like the API server,
+// checking resource versions on updates and deletions.
+type fakeLeases struct {
+	mu      sync.Mutex
+	leases  map[string]lease
+	version int
+}
+
+func (f *fakeLeases) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := path.Base(r.URL.Path)
+	switch r.Method {
+	case http.MethodPost:
+		var l lease
+		json.NewDecoder(r.Body).Decode(&l)
+		if _, ok := f.leases[l.Metadata.Name]; ok {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.store(l)
+	case http.MethodGet:
+		l, ok := f.leases[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(l)
+	case http.MethodPut:
+		var l lease
+		json.NewDecoder(r.Body).Decode(&l)
+		if cur, ok := f.leases[name]; !ok || cur.Metadata.ResourceVersion != l.Metadata.ResourceVersion {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.store(l)
+	case http.MethodDelete:
+		delete(f.leases, name)
+	}
+}
+
+func (f *fakeLeases) store(l lease) {
+	f.version++
+	l.Metadata.ResourceVersion = strconv.Itoa(f.version)
+	f.leases[l.Metadata.Name] = l
+}
+
+// expire backdates the renewal of lease name past its duration.
+func (f *fakeLeases) expire(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	l := f.leases[name]
+	l.Spec.RenewTime = time.Now().Add(-time.Hour).UTC().Format(microTime)
+	f.leases[name] = l
+}
+
+func newFakeKubernetes(t *testing.T, identity string) (*Kubernetes, *fakeLeases) {
+	f := &fakeLeases{leases: make(map[string]lease)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return &Kubernetes{
+		Client:    srv.Client(),
+		Host:      srv.URL,
+		Namespace: "default",
+		Prefix:    "xtid",
+		Identity:  identity,
+		Duration:  time.Minute,
+	}, f
+}
+
+func TestKubernetesSameIdentity(t *testing.T) {
+	ctx := context.Background()
+	b, f := newFakeKubernetes(t, "pod-0")
+	// A second claimant of the same pod, such as a restarted process
+	b2 := &Kubernetes{Client: b.Client, Host: b.Host, Namespace: b.Namespace, Prefix: b.Prefix, Identity: b.Identity, Duration: b.Duration}
+
+	for _, tt := range []struct {
+		b    *Kubernetes
+		want uint64
+	}{{b, 0}, {b, 1}, {b2, 2}} {
+		node, err := tt.b.Claim(ctx, 4)
+		if err != nil || node != tt.want {
+			t.Fatalf("Claim = %d, %v, want %d", node, err, tt.want)
+		}
+	}
+
+	// Node 0 is abandoned, and taken over by b2: b lost it
+	f.expire("xtid-0")
+	if node, err := b2.Claim(ctx, 4); err != nil || node != 0 {
+		t.Fatalf("Claim of expired lease = %d, %v, want 0", node, err)
+	}
+	if err := b.Renew(ctx, 0); err == nil {
+		t.Error("Renew of a lease taken over succeeded")
+	}
+	if err := b.Release(ctx, 0); err == nil {
+		t.Error("Release of a lease taken over succeeded")
+	}
+	if err := b2.Renew(ctx, 0); err != nil {
+		t.Errorf("Renew: %v", err)
+	}
+
+	if err := b.Release(ctx, 1); err != nil {
+		t.Errorf("Release: %v", err)
+	}
+	if err := b.Renew(ctx, 1); !errors.Is(err, errNotClaimed) {
+		t.Errorf("Renew after Release = %v, want %v", err, errNotClaimed)
+	}
+	if node, err := b2.Claim(ctx, 4); err != nil || node != 1 {
+		t.Fatalf("Claim of released lease = %d, %v, want 1", node, err)
+	}
+	if _, err := b2.Claim(ctx, 3); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Claim = %v, want %v", err, ErrExhausted)
+	}
+}
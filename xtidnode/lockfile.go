@@ -0,0 +1,185 @@
+package xtidnode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Name of the file of Dir locked while a claimant inspects or changes the
+// lock files
+const guardName = ".xtidnode.lock"
+
+// LockFile is a Backend claiming node IDs by creating lock files in a
+// directory shared by the claimants, typically on a single host. A lock file
+// whose modification time is older than TTL is considered abandoned and may
+// be taken over.
+//
+// Every claim writes a unique token to its lock file, which Renew and
+// Release check, so that a process which lost its claim learns it instead of
+// renewing or removing the lock of another. Claimants take an exclusive
+// lock on a guard file of Dir, flock(2) or LockFileEx, around every
+// operation, so that two of them never take over the same abandoned lock.
+// The directory must thus support these locks, which network file systems
+// may not.
+type LockFile struct {
+	Dir string
+	TTL time.Duration
+
+	mu     sync.Mutex
+	tokens map[uint64][]byte
+}
+
+// NewLockFile returns a LockFile backend storing its locks in dir.
+func NewLockFile(dir string, ttl time.Duration) *LockFile {
+	return &LockFile{Dir: dir, TTL: ttl}
+}
+
+func (b *LockFile) path(node uint64) string {
+	return filepath.Join(b.Dir, fmt.Sprintf("node-%d.lock", node))
+}
+
+// guard locks the guard file of Dir, and returns the function unlocking it.
+func (b *LockFile) guard() (func(), error) {
+	f, err := os.OpenFile(filepath.Join(b.Dir, guardName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("xtidnode: locking %s: %w", f.Name(), err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+func (b *LockFile) Claim(ctx context.Context, max uint64) (uint64, error) {
+	if err := os.MkdirAll(b.Dir, 0o755); err != nil {
+		return 0, err
+	}
+	unlock, err := b.guard()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	for node := uint64(0); node < max; node++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		ok, err := b.tryClaim(node)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return node, nil
+		}
+	}
+	return 0, ErrExhausted
+}
+
+// tryClaim claims node if its lock file is missing or abandoned. The caller
+// must hold the guard.
+func (b *LockFile) tryClaim(node uint64) (bool, error) {
+	p := b.path(node)
+	fi, err := os.Stat(p)
+	switch {
+	case err == nil && time.Since(fi.ModTime()) < b.TTL:
+		return false, nil
+	case err == nil:
+		// Abandoned, no other claimant can take it over meanwhile
+		if err := os.Remove(p); err != nil {
+			return false, err
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return false, err
+	}
+
+	n, err := nonce()
+	if err != nil {
+		return false, err
+	}
+	token := fmt.Appendf(nil, "%d %s\n", os.Getpid(), n)
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return false, err
+	}
+	if _, err := f.Write(token); err != nil {
+		f.Close()
+		os.Remove(p)
+		return false, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(p)
+		return false, err
+	}
+
+	b.mu.Lock()
+	if b.tokens == nil {
+		b.tokens = make(map[uint64][]byte)
+	}
+	b.tokens[node] = token
+	b.mu.Unlock()
+	return true, nil
+}
+
+// check fails unless the lock file of node holds our token. The caller must
+// hold the guard.
+func (b *LockFile) check(node uint64) error {
+	b.mu.Lock()
+	token, ok := b.tokens[node]
+	b.mu.Unlock()
+	if !ok {
+		return errNotClaimed
+	}
+	got, err := os.ReadFile(b.path(node))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if !bytes.Equal(got, token) {
+		return fmt.Errorf("xtidnode: lock of node ID %d lost", node)
+	}
+	return nil
+}
+
+// Renew refreshes the modification time of the lock file of node, failing
+// if it no longer holds our token.
+func (b *LockFile) Renew(ctx context.Context, node uint64) error {
+	unlock, err := b.guard()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := b.check(node); err != nil {
+		return err
+	}
+	now := time.Now()
+	return os.Chtimes(b.path(node), now, now)
+}
+
+// Release removes the lock file of node, unless it no longer holds our
+// token, in which case it is left to its new owner.
+func (b *LockFile) Release(ctx context.Context, node uint64) error {
+	unlock, err := b.guard()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = b.check(node)
+	b.mu.Lock()
+	delete(b.tokens, node)
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.Remove(b.path(node))
+}
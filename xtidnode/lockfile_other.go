@@ -0,0 +1,18 @@
+//go:build !(unix && !solaris && !illumos && !aix) && !windows
+
+package xtidnode
+
+import (
+	"errors"
+	"os"
+)
+
+var errNoFileLocks = errors.New("xtidnode: LockFile needs file locks, unsupported on this platform")
+
+func lockFile(f *os.File) error {
+	return errNoFileLocks
+}
+
+func unlockFile(f *os.File) error {
+	return errNoFileLocks
+}
@@ -0,0 +1,97 @@
+package xtidnode
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// requireFileLocks skips the test on platforms without file locks.
+func requireFileLocks(t *testing.T) {
+	unlock, err := NewLockFile(t.TempDir(), time.Minute).guard()
+	if err != nil {
+		t.Skip(err)
+	}
+	unlock()
+}
+
+func TestLockFile(t *testing.T) {
+	requireFileLocks(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	a, b := NewLockFile(dir, time.Minute), NewLockFile(dir, time.Minute)
+
+	if node, err := a.Claim(ctx, 2); err != nil || node != 0 {
+		t.Fatalf("Claim = %d, %v, want 0", node, err)
+	}
+	if node, err := b.Claim(ctx, 2); err != nil || node != 1 {
+		t.Fatalf("Claim = %d, %v, want 1", node, err)
+	}
+	if _, err := b.Claim(ctx, 2); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("Claim = %v, want %v", err, ErrExhausted)
+	}
+	if err := b.Renew(ctx, 0); err == nil {
+		t.Error("Renew of a node claimed by another succeeded")
+	}
+
+	// Node 0 is abandoned, and taken over by b: a lost it
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(a.path(0), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if node, err := b.Claim(ctx, 2); err != nil || node != 0 {
+		t.Fatalf("Claim of abandoned node = %d, %v, want 0", node, err)
+	}
+	if err := a.Renew(ctx, 0); err == nil {
+		t.Error("Renew of a node taken over succeeded")
+	}
+	if err := a.Release(ctx, 0); err == nil {
+		t.Error("Release of a node taken over succeeded")
+	}
+	if err := b.Renew(ctx, 0); err != nil {
+		t.Errorf("Renew: %v", err)
+	}
+	if err := b.Release(ctx, 0); err != nil {
+		t.Errorf("Release: %v", err)
+	}
+	if _, err := os.Stat(a.path(0)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file left after Release: %v", err)
+	}
+}
+
+func TestLockFileConcurrentTakeover(t *testing.T) {
+	requireFileLocks(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	stale := NewLockFile(dir, time.Minute)
+	if _, err := stale.Claim(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(stale.path(0), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// However many claimants race for the abandoned node, one gets it
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	owners := 0
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := NewLockFile(dir, time.Minute).Claim(ctx, 1); err == nil {
+				mu.Lock()
+				owners++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if owners != 1 {
+		t.Errorf("%d claimants took over the node, want 1", owners)
+	}
+}
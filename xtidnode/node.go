@@ -0,0 +1,134 @@
+// Package xtidnode claims unique node IDs for use with xtid.WithNodeID, so
+// fleets of generators do not need manual node ID assignment.
+//
+// A node ID is claimed from a pluggable Backend and kept alive by a
+// background renewal loop until the Lease is released:
+//
+//	lease, err := xtidnode.Claim(ctx, xtidnode.NewLockFile("/var/run/xtid", time.Minute), 256, 20*time.Second)
+//	if err != nil { ... }
+//	defer lease.Release(context.Background())
+//	gen, err := xtid.NewGenerator(xtid.WithNodeID(lease.NodeID(), 1))
+//
+// It is a module of its own, so that only its users depend on
+// golang.org/x/sys, which locks files on Windows.
+package xtidnode
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrExhausted is returned when every node ID in the requested range is
+// already claimed.
+var ErrExhausted = errors.New("xtidnode: no free node ID")
+
+var errNotClaimed = errors.New("xtidnode: node ID not claimed")
+
+// nonce returns a random hex string identifying a claim.
+func nonce() (string, error) {
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw[:]), nil
+}
+
+// A Backend records node ID claims in some shared store.
+type Backend interface {
+	// Claim atomically claims a node ID in [0, max) that is not currently
+	// held by anyone else, returning ErrExhausted if there is none.
+	Claim(ctx context.Context, max uint64) (uint64, error)
+
+	// Renew extends the claim on node. It fails if the claim was lost.
+	Renew(ctx context.Context, node uint64) error
+
+	// Release gives up the claim on node.
+	Release(ctx context.Context, node uint64) error
+}
+
+// A Lease is a claimed node ID kept alive by periodic renewal.
+type Lease struct {
+	backend Backend
+	node    uint64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu   sync.Mutex
+	err  error
+	lost chan struct{}
+}
+
+// Claim claims a node ID in [0, max) from backend and renews it every
+// interval until the returned Lease is released.
+func Claim(ctx context.Context, backend Backend, max uint64, interval time.Duration) (*Lease, error) {
+	node, err := backend.Claim(ctx, max)
+	if err != nil {
+		return nil, err
+	}
+
+	rctx, cancel := context.WithCancel(context.Background())
+	l := &Lease{
+		backend: backend,
+		node:    node,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		lost:    make(chan struct{}),
+	}
+	go l.renew(rctx, interval)
+	return l, nil
+}
+
+// NodeID returns the claimed node ID.
+func (l *Lease) NodeID() uint64 {
+	return l.node
+}
+
+// Lost returns a channel closed when a renewal fails, after which other
+// processes may claim the same node ID. Err reports the failure.
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Err returns the renewal error that caused the lease to be lost, if any.
+func (l *Lease) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Release stops renewing the lease and gives up the node ID.
+func (l *Lease) Release(ctx context.Context) error {
+	l.cancel()
+	<-l.done
+	return l.backend.Release(ctx, l.node)
+}
+
+func (l *Lease) renew(ctx context.Context, interval time.Duration) {
+	defer close(l.done)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := l.backend.Renew(ctx, l.node); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				l.mu.Lock()
+				l.err = err
+				l.mu.Unlock()
+				close(l.lost)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package xtidnode
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// Postgres is a Backend claiming node IDs with session-level advisory locks
+// of the form (Class, node). The locks are held by dedicated connections and
+// vanish with them, so a crashed process never holds on to its node ID.
+type Postgres struct {
+	DB    *sql.DB
+	Class int32
+
+	mu    sync.Mutex
+	conns map[uint64]*sql.Conn
+}
+
+// NewPostgres returns a Postgres backend using db and the advisory lock
+// class class.
+func NewPostgres(db *sql.DB, class int32) *Postgres {
+	return &Postgres{DB: db, Class: class}
+}
+
+func (b *Postgres) Claim(ctx context.Context, max uint64) (uint64, error) {
+	conn, err := b.DB.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for node := uint64(0); node < max; node++ {
+		var ok bool
+		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1, $2)", b.Class, int32(node)).Scan(&ok)
+		if err != nil {
+			conn.Close()
+			return 0, err
+		}
+		if ok {
+			b.mu.Lock()
+			if b.conns == nil {
+				b.conns = make(map[uint64]*sql.Conn)
+			}
+			b.conns[node] = conn
+			b.mu.Unlock()
+			return node, nil
+		}
+	}
+	conn.Close()
+	return 0, ErrExhausted
+}
+
+func (b *Postgres) conn(node uint64) (*sql.Conn, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	conn, ok := b.conns[node]
+	if !ok {
+		return nil, errors.New("xtidnode: node ID not claimed")
+	}
+	return conn, nil
+}
+
+// Renew checks that the connection holding the lock on node, and therefore
+// the lock itself, is still alive.
+func (b *Postgres) Renew(ctx context.Context, node uint64) error {
+	conn, err := b.conn(node)
+	if err != nil {
+		return err
+	}
+	return conn.PingContext(ctx)
+}
+
+func (b *Postgres) Release(ctx context.Context, node uint64) error {
+	conn, err := b.conn(node)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	delete(b.conns, node)
+	b.mu.Unlock()
+
+	_, err = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1, $2)", b.Class, int32(node))
+	if cerr := conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
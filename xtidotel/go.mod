@@ -0,0 +1,11 @@
+module github.com/it512/xtid/xtidotel
+
+go 1.23
+
+require (
+	github.com/it512/xtid v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.33.0
+	go.opentelemetry.io/otel/trace v1.33.0
+)
+
+replace github.com/it512/xtid => ../
@@ -0,0 +1,49 @@
+// Package xtidotel correlates XTIDs with OpenTelemetry traces. It is a module
+// of its own, so that only its users depend on the OpenTelemetry API.
+package xtidotel
+
+import (
+	"context"
+	"crypto/sha256"
+
+	"github.com/it512/xtid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Key is the span attribute key under which XTIDs are recorded.
+const Key = attribute.Key("xtid")
+
+// TypeKey is the span attribute key under which the type of a recorded XTID
+// is stored.
+const TypeKey = attribute.Key("xtid.type")
+
+// Attribute returns the attribute recording id under Key.
+func Attribute(id xtid.XTID) attribute.KeyValue {
+	return Key.String(id.String())
+}
+
+// Record sets the attributes describing id on the span found in ctx. It is
+// a no-op when ctx carries no recording span.
+func Record(ctx context.Context, id xtid.XTID) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(Attribute(id), TypeKey.Int(int(id.Type())))
+}
+
+// TraceID deterministically maps id to a trace ID, so that the trace of the
+// request which created an entity can be found from the entity ID alone. The
+// mapping is a truncated SHA-256 of the binary form, adjusted so that the
+// result is always a valid trace ID.
+func TraceID(id xtid.XTID) trace.TraceID {
+	sum := sha256.Sum256(id.Bytes())
+
+	var tid trace.TraceID
+	copy(tid[:], sum[:])
+	if !tid.IsValid() {
+		tid[len(tid)-1] = 1
+	}
+	return tid
+}
@@ -0,0 +1,6 @@
+// Package xtidpb contains the protobuf definitions and generated gRPC stubs
+// of the XTID IDService. It is a module of its own, like xtidsvc, so that
+// only their users depend on gRPC and protobuf.
+package xtidpb
+
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative xtidpb/xtid.proto
@@ -0,0 +1,90 @@
+// Package xtidredis helps using XTIDs with Redis through go-redis.
+//
+// Key and Keys build keys of the form "prefix:id", and ScanIDs reads the
+// values returned by MGET into XTIDs:
+//
+//	ids, err := xtidredis.ScanIDs(rdb.MGet(ctx, xtidredis.Keys("session", users)...).Val())
+//
+// XTID fields of structs filled by HGETALL are supported through the
+// ScanRedis method of xtid.XTID, which accepts both the binary values
+// go-redis writes for XTID arguments and string-encoded ones.
+//
+// go-redis is not a dependency of this package, which only deals with the
+// values it hands out.
+package xtidredis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/it512/xtid"
+)
+
+// Separator joins the parts of the keys built by Key and Keys.
+const Separator = ":"
+
+// Length of string-encoded XTIDs
+const encodedLength = 27
+
+// Key returns the key made of prefix, the string encoding of id and the
+// suffixes, joined by Separator:
+//
+//	xtidredis.Key("user", id, "sessions") // "user:<id>:sessions"
+func Key(prefix string, id xtid.XTID, suffixes ...string) string {
+	var b strings.Builder
+	b.Grow(len(prefix) + len(Separator) + encodedLength)
+	b.WriteString(prefix)
+	b.WriteString(Separator)
+	var buf [encodedLength]byte
+	b.Write(id.Append(buf[:0]))
+	for _, s := range suffixes {
+		b.WriteString(Separator)
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// Keys returns the keys of ids under prefix, as passed to MGET or DEL.
+func Keys(prefix string, ids []xtid.XTID) []string {
+	keys := make([]string, len(ids))
+	for j, id := range ids {
+		keys[j] = Key(prefix, id)
+	}
+	return keys
+}
+
+// ScanIDs converts the values of an MGET, HMGET or LRANGE reply to XTIDs.
+// Missing values, which Redis returns as nil, become xtid.Nil. Values may be
+// string-encoded or binary; invalid ones are reported as *xtid.ParseError.
+func ScanIDs(vals []any) ([]xtid.XTID, error) {
+	ids := make([]xtid.XTID, len(vals))
+	for j, v := range vals {
+		var s string
+		switch v := v.(type) {
+		case nil:
+			continue
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		default:
+			return nil, fmt.Errorf("xtidredis: unexpected %T value at index %d", v, j)
+		}
+		if err := ids[j].ScanRedis(s); err != nil {
+			return nil, &xtid.ParseError{Index: j, Input: s, Err: err}
+		}
+	}
+	return ids, nil
+}
+
+// ScanStrings is ScanIDs for replies read as strings, such as those of
+// SMEMBERS or a StringSliceCmd.
+func ScanStrings(vals []string) ([]xtid.XTID, error) {
+	ids := make([]xtid.XTID, len(vals))
+	for j, s := range vals {
+		if err := ids[j].ScanRedis(s); err != nil {
+			return nil, &xtid.ParseError{Index: j, Input: s, Err: err}
+		}
+	}
+	return ids, nil
+}
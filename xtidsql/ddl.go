@@ -0,0 +1,120 @@
+package xtidsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/it512/xtid"
+)
+
+// A Dialect is a SQL database flavour, for which Preamble and Columns give
+// the recommended definitions of XTID columns.
+type Dialect int
+
+const (
+	// Postgres declares the IDs with a DOMAIN over text in the "C"
+	// collation, checking their length and alphabet.
+	Postgres Dialect = iota
+	// MySQL stores the IDs in BINARY(20) columns, written through Binary
+	// and selected with Range.WhereBinary, with a generated column showing
+	// them in hex.
+	MySQL
+	// SQLite stores the IDs as TEXT, checking their length and alphabet.
+	SQLite
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	case SQLite:
+		return "sqlite"
+	default:
+		return fmt.Sprintf("Dialect(%d)", int(d))
+	}
+}
+
+// ParseDialect returns the Dialect named s, as returned by Dialect.String.
+func ParseDialect(s string) (Dialect, error) {
+	switch strings.ToLower(s) {
+	case "postgres", "postgresql":
+		return Postgres, nil
+	case "mysql":
+		return MySQL, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	default:
+		return 0, fmt.Errorf("xtidsql: unknown dialect %q", s)
+	}
+}
+
+// DefaultDomain is the name of the Postgres domain of XTIDs when none is
+// given.
+const DefaultDomain = "xtid"
+
+// The check of the string form of the IDs, on top of their length: base62
+// digits, and no more than the largest ID. The digits sort in ASCII order,
+// so the comparison holds under a binary collation.
+var (
+	postgresCheck = fmt.Sprintf("VALUE ~ '^[0-9A-Za-z]{27}$' AND VALUE <= '%s'", xtid.Max)
+	sqliteCheck   = "length(%[1]s) = 27 AND %[1]s NOT GLOB '*[^0-9A-Za-z]*' AND %[1]s <= '" + xtid.Max.String() + "'"
+)
+
+// Preamble returns the statements to run once per schema before creating
+// the tables with XTID columns: the CREATE DOMAIN statement of domain, or
+// DefaultDomain when empty, for Postgres, and none for the other dialects.
+// domain is inserted as is, and must not come from user input.
+func Preamble(d Dialect, domain string) []string {
+	if d != Postgres {
+		return nil
+	}
+	if domain == "" {
+		domain = DefaultDomain
+	}
+	return []string{fmt.Sprintf(`CREATE DOMAIN %s AS text COLLATE "C" CHECK (%s)`, domain, postgresCheck)}
+}
+
+// Columns returns the definitions of XTID column col, to be inserted in a
+// CREATE TABLE statement, the ID column first. With MySQL, the second one is
+// the generated column col_hex. domain is the Postgres domain declared by
+// Preamble, DefaultDomain when empty, and is ignored by the other dialects.
+// col and domain are inserted as is, and must not come from user input.
+func Columns(d Dialect, col, domain string) ([]string, error) {
+	switch d {
+	case Postgres:
+		if domain == "" {
+			domain = DefaultDomain
+		}
+		return []string{col + " " + domain + " NOT NULL"}, nil
+	case MySQL:
+		return []string{
+			col + " BINARY(20) NOT NULL",
+			fmt.Sprintf("%[1]s_hex CHAR(40) AS (LOWER(HEX(%[1]s))) VIRTUAL", col),
+		}, nil
+	case SQLite:
+		return []string{col + " TEXT NOT NULL CHECK (" + fmt.Sprintf(sqliteCheck, col) + ")"}, nil
+	default:
+		return nil, fmt.Errorf("xtidsql: unknown dialect %v", d)
+	}
+}
+
+// Binary is a XTID stored in its binary form, as in the BINARY(20) columns
+// of the MySQL dialect, rather than in its string form like xtid.XTID.
+type Binary xtid.XTID
+
+// Value implements the driver.Valuer interface, storing the ID as 20 bytes,
+// or as NULL when it is Nil.
+func (b Binary) Value() (driver.Value, error) {
+	if xtid.XTID(b).IsNil() {
+		return nil, nil
+	}
+	return b[:], nil
+}
+
+// Scan implements the sql.Scanner interface, like xtid.XTID.Scan.
+func (b *Binary) Scan(src any) error {
+	return (*xtid.XTID)(b).Scan(src)
+}
@@ -0,0 +1,107 @@
+// Package xtidsql builds the SQL conditions selecting XTIDs by time, and the
+// definitions of the columns holding them.
+//
+// IDs are stored string-encoded by database/sql, see xtid.XTID.Value, and
+// string-encoded IDs sort by time, so a time range translates to a range of
+// IDs. Its bounds are not the IDs of the first and last rows, though, and
+// BETWEEN includes its upper bound: Range gets both right.
+//
+//	cond, args := xtidsql.Range{From: since, To: until}.WhereDollar("id", 1)
+//	rows, err := db.QueryContext(ctx, "SELECT id, body FROM events WHERE "+cond, args...)
+//
+// The comparisons only follow the order of the IDs under a binary collation:
+// declare the ID columns with COLLATE "C" in Postgres, or a _bin collation
+// in MySQL. The default collation of SQLite is binary. Preamble and Columns
+// give definitions getting this right for each Dialect.
+package xtidsql
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// A Range selects the IDs minted from From included to To excluded. Either
+// bound may be zero, leaving the range open on that side.
+//
+// The bounds assume IDs of microsecond precision, the default; IDs of
+// nanosecond precision sort after all of them.
+type Range struct {
+	From, To time.Time
+}
+
+// Last returns the Range of the IDs minted within the last d.
+func Last(d time.Duration) Range {
+	return Range{From: time.Now().Add(-d)}
+}
+
+// Bounds returns the smallest ID minted at From and at To, and whether
+// each bound is set.
+func (r Range) Bounds() (lo, hi xtid.XTID, hasLo, hasHi bool) {
+	if !r.From.IsZero() {
+		lo, hasLo = xtid.LowerBound(r.From), true
+	}
+	if !r.To.IsZero() {
+		hi, hasHi = xtid.LowerBound(r.To), true
+	}
+	return
+}
+
+// Where returns the condition on column col selecting the IDs of the range,
+// with ? placeholders, and its arguments, the bounds in their string form.
+// col is inserted as is, and must not come from user input. An open range
+// gives the condition "1 = 1".
+func (r Range) Where(col string) (string, []any) {
+	return r.where(col, func(int) string { return "?" }, stringValue)
+}
+
+// WhereDollar is like Where, with the $n placeholders of Postgres numbered
+// from n.
+func (r Range) WhereDollar(col string, n int) (string, []any) {
+	return r.where(col, func(j int) string { return "$" + strconv.Itoa(n+j) }, stringValue)
+}
+
+// WhereBinary is like Where, for columns holding the IDs in their binary
+// form, such as the BINARY(20) columns of the MySQL dialect: it binds the
+// bounds as bytes.
+func (r Range) WhereBinary(col string) (string, []any) {
+	return r.where(col, func(int) string { return "?" }, binaryValue)
+}
+
+// WhereFor returns the condition of the range on column col as defined by
+// Columns for dialect d, binding the bounds in the form the column holds
+// them, with the placeholders of d numbered from 1.
+func (r Range) WhereFor(d Dialect, col string) (string, []any) {
+	switch d {
+	case Postgres:
+		return r.WhereDollar(col, 1)
+	case MySQL:
+		return r.WhereBinary(col)
+	default:
+		return r.Where(col)
+	}
+}
+
+func stringValue(id xtid.XTID) any { return id.String() }
+
+func binaryValue(id xtid.XTID) any { return id[:] }
+
+func (r Range) where(col string, placeholder func(j int) string, value func(xtid.XTID) any) (string, []any) {
+	lo, hi, hasLo, hasHi := r.Bounds()
+	var conds []string
+	var args []any
+	if hasLo {
+		conds = append(conds, col+" >= "+placeholder(len(args)))
+		args = append(args, value(lo))
+	}
+	if hasHi {
+		conds = append(conds, col+" < "+placeholder(len(args)))
+		args = append(args, value(hi))
+	}
+	if len(conds) == 0 {
+		return "1 = 1", nil
+	}
+	return strings.Join(conds, " AND "), args
+}
@@ -0,0 +1,140 @@
+// Package xtidstats analyzes sets of XTIDs using their timestamps alone,
+// such as the IDs of a log or an export, without the records they identify.
+package xtidstats
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// A Histogram counts IDs per time interval, and per type within each
+// interval:
+//
+//	h := xtidstats.NewHistogram(time.Minute)
+//	h.AddSeq(ids)
+//	for _, b := range h.Buckets() {
+//		fmt.Println(b.Start, b.Count, b.Types)
+//	}
+//
+// It takes memory proportional to the number of intervals and types, not
+// to the number of IDs. A Histogram is not safe for concurrent use.
+type Histogram struct {
+	// Length of the intervals in microseconds
+	interval int64
+	buckets  map[int64]*Bucket
+	types    map[uint16]int64
+	total    int64
+}
+
+// A Bucket holds the counts of an interval.
+type Bucket struct {
+	// Start is the beginning of the interval, in UTC.
+	Start time.Time
+	// Count is the number of IDs minted within the interval.
+	Count int64
+	// Types counts the IDs of each type minted within the interval.
+	Types map[uint16]int64
+}
+
+// MaxEmptyBuckets is the longest run of empty intervals returned by
+// Buckets between two intervals holding IDs.
+const MaxEmptyBuckets = 1000
+
+// NewHistogram returns a Histogram with intervals of the given length,
+// aligned on the Unix epoch, so that intervals of a minute start on whole
+// minutes. Intervals are whole microseconds, at least one.
+func NewHistogram(interval time.Duration) *Histogram {
+	return &Histogram{
+		interval: max(interval.Microseconds(), 1),
+		buckets:  make(map[int64]*Bucket),
+		types:    make(map[uint16]int64),
+	}
+}
+
+// Add counts id.
+func (h *Histogram) Add(id xtid.XTID) {
+	k := floorDiv(unixMicro(id), h.interval)
+	b := h.buckets[k]
+	if b == nil {
+		b = &Bucket{Start: h.start(k), Types: make(map[uint16]int64)}
+		h.buckets[k] = b
+	}
+	b.Count++
+	b.Types[id.Type()]++
+	h.types[id.Type()]++
+	h.total++
+}
+
+// AddSeq counts the IDs of seq.
+func (h *Histogram) AddSeq(seq iter.Seq[xtid.XTID]) {
+	for id := range seq {
+		h.Add(id)
+	}
+}
+
+// Total returns the number of IDs counted.
+func (h *Histogram) Total() int64 {
+	return h.total
+}
+
+// Types returns the types of the IDs counted, in increasing order.
+func (h *Histogram) Types() []uint16 {
+	return slices.Sorted(maps.Keys(h.types))
+}
+
+// TypeCount returns the number of IDs of type typ counted.
+func (h *Histogram) TypeCount(typ uint16) int64 {
+	return h.types[typ]
+}
+
+// Buckets returns the intervals from the oldest to the newest one holding
+// IDs, in order, including the empty intervals between them, up to
+// MaxEmptyBuckets in a row: longer gaps, as left by stray IDs far from the
+// others, are skipped, and show as a jump in the starts of the intervals.
+func (h *Histogram) Buckets() []Bucket {
+	if len(h.buckets) == 0 {
+		return nil
+	}
+	keys := slices.Sorted(maps.Keys(h.buckets))
+	var buckets []Bucket
+	for j, k := range keys {
+		if j > 0 {
+			if gap := k - keys[j-1] - 1; gap <= MaxEmptyBuckets {
+				for e := keys[j-1] + 1; e < k; e++ {
+					buckets = append(buckets, Bucket{Start: h.start(e), Types: map[uint16]int64{}})
+				}
+			}
+		}
+		b := h.buckets[k]
+		buckets = append(buckets, Bucket{Start: b.Start, Count: b.Count, Types: maps.Clone(b.Types)})
+	}
+	return buckets
+}
+
+func (h *Histogram) start(k int64) time.Time {
+	return time.UnixMicro(k * h.interval).UTC()
+}
+
+// unixMicro returns the time of id in microseconds since the Unix epoch,
+// computed from its raw timestamp so that it doesn't overflow like
+// Time().UnixNano() does for IDs minted after 2262.
+func unixMicro(id xtid.XTID) int64 {
+	us := int64(id.Timestamp())
+	if id.Precision() == time.Nanosecond {
+		us /= 1e3
+	}
+	return us + xtid.Epoch().UnixMicro()
+}
+
+// floorDiv divides a by b > 0, rounding towards negative infinity.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b < 0 {
+		q--
+	}
+	return q
+}
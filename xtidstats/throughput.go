@@ -0,0 +1,152 @@
+package xtidstats
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// Defaults of the Throughput settings
+const (
+	DefaultWindow      = time.Minute
+	DefaultBurstFactor = 3
+	// Gaps are DefaultGapFactor times longer than the median time between
+	// IDs by default.
+	DefaultGapFactor = 10
+)
+
+var errUnsorted = errors.New("xtidstats: IDs are not sorted")
+
+// Throughput estimates the rate at which IDs were created from a sorted
+// sample of them, as when reconstructing the behavior of a producer from
+// stored IDs, and flags the gaps and bursts in it. The zero value uses the
+// defaults.
+type Throughput struct {
+	// Window is the length of the intervals over which rates are
+	// estimated, DefaultWindow when zero.
+	Window time.Duration
+	// Sampling is the fraction of the IDs the sample holds, by which counts
+	// are scaled, 1 when zero.
+	Sampling float64
+	// Gap is the time between two consecutive IDs above which it is
+	// reported as a gap, DefaultGapFactor times the median time between
+	// IDs when zero.
+	Gap time.Duration
+	// BurstFactor is the ratio to the median rate above which the rate of
+	// a window is reported as a burst, DefaultBurstFactor when zero.
+	BurstFactor float64
+}
+
+// A Rate is the estimated creation rate of IDs within a window.
+type Rate struct {
+	Start time.Time
+	// Count is the estimated number of IDs created within the window.
+	Count float64
+	// PerSecond is the estimated number of IDs created per second.
+	PerSecond float64
+}
+
+// A Gap is a period without IDs.
+type Gap struct {
+	// From and To are the times of the IDs around the gap.
+	From, To time.Time
+}
+
+// Duration returns the length of the gap.
+func (g Gap) Duration() time.Duration {
+	return g.To.Sub(g.From)
+}
+
+// A ThroughputReport holds the estimates of Throughput.Analyze.
+type ThroughputReport struct {
+	// Rates holds the rate of every window, in order.
+	Rates []Rate
+	// Median is the median rate of the windows holding IDs.
+	Median float64
+	// Mean is the mean rate over the whole sample, in IDs per second.
+	Mean float64
+	// Gaps and Bursts are the gaps and the windows of bursts found, in
+	// order.
+	Gaps   []Gap
+	Bursts []Rate
+}
+
+// Analyze estimates the creation rate of the IDs of sample, which must be
+// sorted.
+func (t Throughput) Analyze(sample []xtid.XTID) (ThroughputReport, error) {
+	var r ThroughputReport
+	if !slices.IsSortedFunc(sample, xtid.Compare) {
+		return r, errUnsorted
+	}
+	if len(sample) == 0 {
+		return r, nil
+	}
+	window := t.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	scale := 1.0
+	if t.Sampling > 0 {
+		scale = 1 / t.Sampling
+	}
+
+	first, last := sample[0].Time(), sample[len(sample)-1].Time()
+	span := last.Sub(first)
+	if span > 0 {
+		r.Mean = float64(len(sample)) * scale / span.Seconds()
+	}
+
+	gap := t.Gap
+	if gap <= 0 && len(sample) > 1 {
+		intervals := make([]float64, len(sample)-1)
+		for j := range intervals {
+			intervals[j] = float64(sample[j+1].Time().Sub(sample[j].Time()))
+		}
+		gap = DefaultGapFactor * time.Duration(median(intervals))
+	}
+	for j := 1; j < len(sample) && gap > 0; j++ {
+		from, to := sample[j-1].Time(), sample[j].Time()
+		if to.Sub(from) > gap {
+			r.Gaps = append(r.Gaps, Gap{From: from, To: to})
+		}
+	}
+
+	h := NewHistogram(window)
+	for _, id := range sample {
+		h.Add(id)
+	}
+	var rates []float64
+	for _, b := range h.Buckets() {
+		rate := Rate{Start: b.Start, Count: float64(b.Count) * scale}
+		rate.PerSecond = rate.Count / window.Seconds()
+		r.Rates = append(r.Rates, rate)
+		if b.Count > 0 {
+			rates = append(rates, rate.PerSecond)
+		}
+	}
+	r.Median = median(rates)
+
+	factor := t.BurstFactor
+	if factor <= 0 {
+		factor = DefaultBurstFactor
+	}
+	for _, rate := range r.Rates {
+		if rate.PerSecond > factor*r.Median {
+			r.Bursts = append(r.Bursts, rate)
+		}
+	}
+	return r, nil
+}
+
+func median(v []float64) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	slices.Sort(v)
+	if n := len(v); n%2 == 0 {
+		return (v[n/2-1] + v[n/2]) / 2
+	}
+	return v[len(v)/2]
+}
@@ -0,0 +1,86 @@
+// Package xtidstore converts XTIDs to and from the values stored by
+// Firestore and Datastore.
+//
+// Neither client knows XTIDs: left alone, they store the array type as a
+// list of 20 integers, which can't be compared in queries. This package
+// stores them as bytes, whose ordering in both databases is that of the
+// IDs, so that range queries on IDs, and thus on their times, work:
+//
+//	lo, hi := xtidstore.Range(since, until)
+//	q := events.Where("id", ">=", lo).Where("id", "<", hi)
+//
+// Documents written as maps go through EncodeMap, and documents read with
+// DocumentSnapshot.Data through FromValue. With Datastore, Value and
+// FromValue are meant for the Save and Load methods of a
+// PropertyLoadSaver:
+//
+//	func (e *Event) Save() ([]datastore.Property, error) {
+//		props, err := datastore.SaveStruct(e)
+//		return append(props, datastore.Property{Name: "ID", Value: xtidstore.Value(e.ID)}), err
+//	}
+//
+// Neither client is a dependency of this package.
+package xtidstore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// Value returns the value id is stored as, its binary form, or nil for
+// xtid.Nil.
+func Value(id xtid.XTID) any {
+	if id.IsNil() {
+		return nil
+	}
+	return id.Bytes()
+}
+
+// Values returns the values ids are stored as, for array fields and "in"
+// queries.
+func Values(ids []xtid.XTID) []any {
+	vals := make([]any, len(ids))
+	for j, id := range ids {
+		vals[j] = Value(id)
+	}
+	return vals
+}
+
+// FromValue converts a stored value back to an XTID. It accepts the binary
+// values written by Value, string-encoded IDs, and nil, which is xtid.Nil.
+func FromValue(v any) (xtid.XTID, error) {
+	switch v.(type) {
+	case nil, []byte, string:
+	default:
+		return xtid.Nil, fmt.Errorf("xtidstore: can't convert %T to an XTID", v)
+	}
+	var id xtid.XTID
+	if err := id.Scan(v); err != nil {
+		return xtid.Nil, err
+	}
+	return id, nil
+}
+
+// EncodeMap replaces the XTIDs and lists of XTIDs found among the values of
+// m, at any depth, by their stored values, before m is written.
+func EncodeMap(m map[string]any) {
+	for k, v := range m {
+		switch v := v.(type) {
+		case xtid.XTID:
+			m[k] = Value(v)
+		case []xtid.XTID:
+			m[k] = Values(v)
+		case map[string]any:
+			EncodeMap(v)
+		}
+	}
+}
+
+// Range returns the bounds of the stored values of the IDs minted from lo
+// included to hi excluded with microsecond precision, whatever their type.
+// A zero lo selects the IDs from the epoch on, see xtid.LowerBound.
+func Range(lo, hi time.Time) (from, to []byte) {
+	return xtid.LowerBound(lo).Bytes(), xtid.LowerBound(hi).Bytes()
+}
@@ -0,0 +1,88 @@
+package xtid
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+)
+
+var errJSONString = errors.New("xtid: XTIDs must be JSON strings")
+
+// XTIDStr wraps a XTID, and caches its string representation on first use,
+// for IDs rendered over and over in JSON and logs. Its methods other than
+// the Unmarshal ones are safe for concurrent use.
+//
+// XTIDStr is meant to be held by value, in structs and maps: the copies
+// share the cache, and marshal like the original. The zero XTIDStr holds the
+// Nil XTID, and has no cache: it computes the string on every use.
+type XTIDStr struct {
+	id XTID
+	s  *atomic.Pointer[string]
+}
+
+// NewXTIDStr returns a XTIDStr wrapping id.
+func NewXTIDStr(id XTID) XTIDStr {
+	return XTIDStr{id: id, s: new(atomic.Pointer[string])}
+}
+
+// XTID returns the wrapped ID.
+func (x XTIDStr) XTID() XTID {
+	return x.id
+}
+
+// IsZero reports whether x holds the Nil XTID, see XTID.IsZero.
+func (x XTIDStr) IsZero() bool {
+	return x.id.IsNil()
+}
+
+// String returns the string representation of the ID, computing it on the
+// first call only.
+func (x XTIDStr) String() string {
+	if x.s == nil {
+		return x.id.String()
+	}
+	if s := x.s.Load(); s != nil {
+		return *s
+	}
+	s := x.id.String()
+	x.s.Store(&s)
+	return s
+}
+
+func (x XTIDStr) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+func (x *XTIDStr) UnmarshalText(b []byte) error {
+	id, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	// A new cache, since the copies of x share the old one
+	*x = NewXTIDStr(id)
+	return nil
+}
+
+// MarshalJSON encodes the ID as a JSON string.
+func (x XTIDStr) MarshalJSON() ([]byte, error) {
+	s := x.String()
+	b := make([]byte, 0, len(s)+2)
+	b = append(b, '"')
+	b = append(b, s...)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON decodes the ID from a JSON string.
+func (x *XTIDStr) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return errJSONString
+	}
+	return x.UnmarshalText([]byte(s))
+}
+
+// LogValue implements slog.LogValuer.
+func (x XTIDStr) LogValue() slog.Value {
+	return slog.StringValue(x.String())
+}
@@ -0,0 +1,90 @@
+// Package xtidsvc is the reference implementation of the xtidpb.IDService
+// gRPC service. It is a module of its own, see xtidpb.
+package xtidsvc
+
+import (
+	"context"
+	"math"
+
+	"github.com/it512/xtid"
+	"github.com/it512/xtid/xtidpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultMaxBatch is the batch size limit used when none is configured.
+const DefaultMaxBatch = 1000
+
+// Server implements xtidpb.IDServiceServer.
+type Server struct {
+	xtidpb.UnimplementedIDServiceServer
+
+	// MaxBatch caps the count accepted by GenerateBatch. DefaultMaxBatch is
+	// used when it is zero.
+	MaxBatch uint32
+}
+
+// NewServer returns a Server with the default limits.
+func NewServer() *Server {
+	return &Server{MaxBatch: DefaultMaxBatch}
+}
+
+func (s *Server) Generate(ctx context.Context, req *xtidpb.GenerateRequest) (*xtidpb.GenerateResponse, error) {
+	typ, err := checkType(req.GetType())
+	if err != nil {
+		return nil, err
+	}
+	id, err := xtid.NewWithType(typ)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "generate: %v", err)
+	}
+	return &xtidpb.GenerateResponse{Id: id.String()}, nil
+}
+
+func (s *Server) GenerateBatch(ctx context.Context, req *xtidpb.GenerateBatchRequest) (*xtidpb.GenerateBatchResponse, error) {
+	typ, err := checkType(req.GetType())
+	if err != nil {
+		return nil, err
+	}
+
+	max := s.MaxBatch
+	if max == 0 {
+		max = DefaultMaxBatch
+	}
+	if req.GetCount() == 0 || req.GetCount() > max {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be between 1 and %d", max)
+	}
+
+	batch, err := xtid.NewBatch(typ, int(req.GetCount()))
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "generate: %v", err)
+	}
+	ids := make([]string, len(batch))
+	for i, id := range batch {
+		ids[i] = id.String()
+	}
+	return &xtidpb.GenerateBatchResponse{Ids: ids}, nil
+}
+
+func (s *Server) Inspect(ctx context.Context, req *xtidpb.InspectRequest) (*xtidpb.InspectResponse, error) {
+	id, err := xtid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "inspect: %v", err)
+	}
+	b := id.Bytes()
+	return &xtidpb.InspectResponse{
+		Id:      id.String(),
+		Binary:  b,
+		Time:    timestamppb.New(id.Time()),
+		Type:    uint32(id.Type()),
+		Payload: b[len(b)-xtid.PayloadLength:],
+	}, nil
+}
+
+func checkType(t uint32) (uint16, error) {
+	if t > math.MaxUint16 {
+		return 0, status.Errorf(codes.InvalidArgument, "type %d does not fit in 16 bits", t)
+	}
+	return uint16(t), nil
+}
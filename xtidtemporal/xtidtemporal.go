@@ -0,0 +1,157 @@
+// Package xtidtemporal serializes XTIDs in Temporal workflow payloads.
+//
+// Left to the default data converter, XTIDs go through JSON. The payload
+// converter of this package stores xtid.XTID and []xtid.XTID values as
+// their binary form instead, 20 bytes per ID, which keeps workflow
+// arguments and history compact and deterministic. Install it on the
+// client:
+//
+//	c, err := client.Dial(client.Options{
+//		DataConverter: xtidtemporal.NewDataConverter(),
+//	})
+//
+// Only top-level arguments and results of those types are affected; XTIDs
+// within structs still go through JSON.
+//
+// It is a module of its own, github.com/it512/xtid/xtidtemporal, so that
+// the users of xtid don't depend on the Temporal SDK.
+package xtidtemporal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/it512/xtid"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// MetadataEncodingXTID is the encoding of the payloads holding XTIDs.
+const MetadataEncodingXTID = "binary/xtid"
+
+// Length of binary XTIDs
+const byteLength = 20
+
+// PayloadConverter converts xtid.XTID and []xtid.XTID values to payloads.
+type PayloadConverter struct{}
+
+// NewPayloadConverter creates a PayloadConverter.
+func NewPayloadConverter() *PayloadConverter {
+	return &PayloadConverter{}
+}
+
+// NewDataConverter returns the default data converter of Temporal, with a
+// PayloadConverter ahead of the JSON one.
+func NewDataConverter() converter.DataConverter {
+	return converter.NewCompositeDataConverter(
+		converter.NewNilPayloadConverter(),
+		converter.NewByteSlicePayloadConverter(),
+		NewPayloadConverter(),
+		converter.NewProtoJSONPayloadConverter(),
+		converter.NewProtoPayloadConverter(),
+		converter.NewJSONPayloadConverter(),
+	)
+}
+
+// ToPayload converts an xtid.XTID, []xtid.XTID or pointer to either to a
+// payload, or returns nil for other values.
+func (c *PayloadConverter) ToPayload(value any) (*commonpb.Payload, error) {
+	var data []byte
+	switch v := value.(type) {
+	case xtid.XTID:
+		data = v.Bytes()
+	case *xtid.XTID:
+		if v == nil {
+			return nil, nil
+		}
+		data = v.Bytes()
+	case []xtid.XTID:
+		data = appendIDs(v)
+	case *[]xtid.XTID:
+		if v == nil {
+			return nil, nil
+		}
+		data = appendIDs(*v)
+	default:
+		return nil, nil
+	}
+	return &commonpb.Payload{
+		Metadata: map[string][]byte{converter.MetadataEncoding: []byte(MetadataEncodingXTID)},
+		Data:     data,
+	}, nil
+}
+
+func appendIDs(ids []xtid.XTID) []byte {
+	data := make([]byte, 0, len(ids)*byteLength)
+	for _, id := range ids {
+		data = append(data, id[:]...)
+	}
+	return data
+}
+
+// FromPayload decodes a payload into valuePtr, which must be a *xtid.XTID,
+// a *[]xtid.XTID, or a pointer to an empty interface, which receives the
+// IDs as a []xtid.XTID.
+func (c *PayloadConverter) FromPayload(payload *commonpb.Payload, valuePtr any) error {
+	data := payload.GetData()
+	if len(data)%byteLength != 0 {
+		return fmt.Errorf("xtidtemporal: payload of %d bytes is not a list of XTIDs", len(data))
+	}
+	switch v := valuePtr.(type) {
+	case *xtid.XTID:
+		if len(data) != byteLength {
+			return fmt.Errorf("xtidtemporal: payload holds %d XTIDs, not one", len(data)/byteLength)
+		}
+		id, err := xtid.FromBytes(data)
+		if err != nil {
+			return err
+		}
+		*v = id
+	case *[]xtid.XTID:
+		ids, err := decodeIDs(data)
+		if err != nil {
+			return err
+		}
+		*v = ids
+	case *any:
+		ids, err := decodeIDs(data)
+		if err != nil {
+			return err
+		}
+		*v = ids
+	default:
+		return fmt.Errorf("type %T: %w", valuePtr, converter.ErrUnableToSetValue)
+	}
+	return nil
+}
+
+func decodeIDs(data []byte) ([]xtid.XTID, error) {
+	ids := make([]xtid.XTID, len(data)/byteLength)
+	for j := range ids {
+		id, err := xtid.FromBytes(data[j*byteLength : (j+1)*byteLength])
+		if err != nil {
+			return nil, err
+		}
+		ids[j] = id
+	}
+	return ids, nil
+}
+
+// ToString returns the string encodings of the IDs of payload, separated by
+// commas.
+func (c *PayloadConverter) ToString(payload *commonpb.Payload) string {
+	var ids []xtid.XTID
+	if err := c.FromPayload(payload, &ids); err != nil {
+		return err.Error()
+	}
+	s := make([]string, len(ids))
+	for j, id := range ids {
+		s[j] = id.String()
+	}
+	return strings.Join(s, ",")
+}
+
+// Encoding returns MetadataEncodingXTID.
+func (c *PayloadConverter) Encoding() string {
+	return MetadataEncodingXTID
+}
@@ -0,0 +1,11 @@
+module github.com/it512/xtid/xtidtest
+
+go 1.23
+
+require (
+	github.com/it512/xtid v0.0.0-00010101000000-000000000000
+	github.com/leanovate/gopter v0.2.9
+	pgregory.net/rapid v1.1.0
+)
+
+replace github.com/it512/xtid => ../
@@ -0,0 +1,36 @@
+package xtidtest
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/it512/xtid"
+	"github.com/leanovate/gopter"
+)
+
+// GenID returns a gopter generator of random XTIDs, see Random.
+func GenID() gopter.Gen {
+	return gen(Random)
+}
+
+// GenInWindow returns a gopter generator of XTIDs timestamped in [from, to),
+// see InWindow.
+func GenInWindow(from, to time.Time) gopter.Gen {
+	return gen(func(r *rand.Rand) xtid.XTID {
+		return InWindow(r, from, to)
+	})
+}
+
+// GenOfType returns a gopter generator of XTIDs of type typ, see OfType.
+func GenOfType(typ uint16) gopter.Gen {
+	return gen(func(r *rand.Rand) xtid.XTID {
+		return OfType(r, typ)
+	})
+}
+
+func gen(f func(*rand.Rand) xtid.XTID) gopter.Gen {
+	return func(p *gopter.GenParameters) *gopter.GenResult {
+		r := rand.New(rand.NewPCG(p.NextUint64(), p.NextUint64()))
+		return gopter.NewGenResult(f(r), gopter.NoShrinker)
+	}
+}
@@ -0,0 +1,35 @@
+package xtidtest
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/it512/xtid"
+	"pgregory.net/rapid"
+)
+
+// RapidID returns a rapid generator of random XTIDs, see Random.
+func RapidID() *rapid.Generator[xtid.XTID] {
+	return rapid.Custom(func(t *rapid.T) xtid.XTID {
+		return OfType(rapidRand(t), rapid.Uint16().Draw(t, "type"))
+	})
+}
+
+// RapidInWindow returns a rapid generator of XTIDs timestamped in
+// [from, to), see InWindow.
+func RapidInWindow(from, to time.Time) *rapid.Generator[xtid.XTID] {
+	return rapid.Custom(func(t *rapid.T) xtid.XTID {
+		return InWindow(rapidRand(t), from, to)
+	})
+}
+
+// RapidOfType returns a rapid generator of XTIDs of type typ, see OfType.
+func RapidOfType(typ uint16) *rapid.Generator[xtid.XTID] {
+	return rapid.Custom(func(t *rapid.T) xtid.XTID {
+		return OfType(rapidRand(t), typ)
+	})
+}
+
+func rapidRand(t *rapid.T) *rand.Rand {
+	return rand.New(rand.NewPCG(rapid.Uint64().Draw(t, "seed1"), rapid.Uint64().Draw(t, "seed2")))
+}
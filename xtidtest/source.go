@@ -0,0 +1,112 @@
+package xtidtest
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrScripted is the error returned by a Source step failing without an
+// explicit error.
+var ErrScripted = errors.New("xtidtest: scripted read failure")
+
+// A Step describes the outcome of one Read call on a Source.
+type Step struct {
+	// Data is copied into the buffer passed to Read. When it is shorter than
+	// the buffer the read is short; when nil the buffer is filled with
+	// Fill.
+	Data []byte
+	// Fill is the byte used to fill the buffer when Data is nil.
+	Fill byte
+	// Err is returned by Read after copying Data.
+	Err error
+}
+
+// Source is an entropy source whose Read calls follow a script, for
+// exercising the error paths of code minting XTIDs:
+//
+//	src := xtidtest.NewSource(xtidtest.Bytes(payload), xtidtest.Fail(nil))
+//	xtid.SetSource(src)
+//
+// Once the script is exhausted, the Source repeats its last step, or fills
+// buffers with zeros when the script is empty. Sources are safe for
+// concurrent use.
+type Source struct {
+	mu    sync.Mutex
+	steps []Step
+	reads int
+}
+
+// NewSource returns a Source following steps.
+func NewSource(steps ...Step) *Source {
+	return &Source{steps: steps}
+}
+
+// Bytes returns a step returning data, which results in a short read if data
+// is smaller than the buffer.
+func Bytes(data []byte) Step {
+	return Step{Data: data}
+}
+
+// Filled returns a step filling the buffer with b.
+func Filled(b byte) Step {
+	return Step{Fill: b}
+}
+
+// Short returns a step returning only n zero bytes, without error. As XTID
+// generation reads with io.ReadFull, follow it with a failing step to make
+// the short read surface as an error.
+func Short(n int) Step {
+	return Step{Data: make([]byte, n)}
+}
+
+// Fail returns a step failing with err, or ErrScripted when err is nil.
+func Fail(err error) Step {
+	if err == nil {
+		err = ErrScripted
+	}
+	return Step{Data: []byte{}, Err: err}
+}
+
+// FailAfter returns a Source succeeding n reads, filling the buffer of the
+// i-th one with the byte i, then failing with err, or ErrScripted when err is
+// nil.
+func FailAfter(n int, err error) *Source {
+	steps := make([]Step, 0, n+1)
+	for i := 0; i < n; i++ {
+		steps = append(steps, Filled(byte(i+1)))
+	}
+	return NewSource(append(steps, Fail(err))...)
+}
+
+// Reads returns the number of Read calls made so far.
+func (s *Source) Reads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reads
+}
+
+func (s *Source) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	step := Step{}
+	switch {
+	case s.reads < len(s.steps):
+		step = s.steps[s.reads]
+	case len(s.steps) > 0:
+		step = s.steps[len(s.steps)-1]
+	}
+	s.reads++
+
+	if step.Data == nil {
+		for i := range p {
+			p[i] = step.Fill
+		}
+		return len(p), step.Err
+	}
+	n := copy(p, step.Data)
+	return n, step.Err
+}
+
+var _ io.Reader = (*Source)(nil)
@@ -0,0 +1,45 @@
+// Package xtidtest provides utilities for testing code handling XTIDs. It is
+// a module of its own, so that only its users depend on rapid and gopter.
+package xtidtest
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// Random returns a random valid XTID drawn from r, timestamped between 2000
+// and 2100.
+func Random(r *rand.Rand) xtid.XTID {
+	return InWindow(r, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// InWindow returns a random XTID drawn from r, timestamped in [from, to).
+func InWindow(r *rand.Rand, from, to time.Time) xtid.XTID {
+	return build(r, from, to, uint16(r.Uint32()))
+}
+
+// OfType returns a random XTID of type typ drawn from r, timestamped between
+// 2000 and 2100.
+func OfType(r *rand.Rand, typ uint16) xtid.XTID {
+	return build(r, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC), typ)
+}
+
+func build(r *rand.Rand, from, to time.Time, typ uint16) xtid.XTID {
+	t := from
+	if span := to.Sub(from); span > 0 {
+		t = from.Add(time.Duration(r.Int64N(int64(span))))
+	}
+
+	var payload [10]byte
+	for i := range payload {
+		payload[i] = byte(r.Uint32())
+	}
+	return fromParts(t, typ, payload)
+}
+
+// fromParts assembles a XTID from its parts.
+func fromParts(t time.Time, typ uint16, payload [10]byte) xtid.XTID {
+	return xtid.MakeWithPayload(t, typ, payload)
+}
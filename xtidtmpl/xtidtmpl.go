@@ -0,0 +1,71 @@
+// Package xtidtmpl provides template functions formatting and decomposing
+// XTIDs, for server-rendered pages:
+//
+//	t := template.New("admin").Funcs(xtidtmpl.FuncMap())
+//
+//	{{ xtid_time .ID }} {{ xtid_type .ID }} {{ xtid_short .ID }}
+//
+// The functions take a xtid.XTID, a pointer to one, or its string encoding.
+// As html/template.FuncMap has the same underlying type, the map can be used
+// with html/template too:
+//
+//	htmltemplate.New("admin").Funcs(htmltemplate.FuncMap(xtidtmpl.FuncMap()))
+package xtidtmpl
+
+import (
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+// The number of trailing characters kept by xtid_short
+const shortLength = 8
+
+// FuncMap returns the template functions:
+//
+//   - xtid_parse parses a string-encoded ID.
+//   - xtid_time returns the time of an ID.
+//   - xtid_type returns the type of an ID.
+//   - xtid_short returns the last 8 characters of the string encoding of an
+//     ID, derived from its payload, for display where the full ID doesn't fit.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"xtid_parse": xtid.Parse,
+		"xtid_time": func(v any) (time.Time, error) {
+			id, err := toID(v)
+			return id.Time(), err
+		},
+		"xtid_type": func(v any) (uint16, error) {
+			id, err := toID(v)
+			return id.Type(), err
+		},
+		"xtid_short": func(v any) (string, error) {
+			id, err := toID(v)
+			if err != nil {
+				return "", err
+			}
+			s := id.String()
+			return s[len(s)-shortLength:], nil
+		},
+	}
+}
+
+func toID(v any) (xtid.XTID, error) {
+	switch v := v.(type) {
+	case xtid.XTID:
+		return v, nil
+	case *xtid.XTID:
+		if v == nil {
+			return xtid.Nil, nil
+		}
+		return *v, nil
+	case string:
+		return xtid.Parse(v)
+	case []byte:
+		return xtid.Parse(string(v))
+	default:
+		return xtid.Nil, fmt.Errorf("xtidtmpl: unable to use type %T as XTID", v)
+	}
+}
@@ -0,0 +1,138 @@
+// Package xtidttl mints XTIDs carrying an expiry, for short-lived handles such
+// as upload URLs or sessions keyed by XTID.
+//
+// The time to live is stored in seconds in the last 4 bytes of the payload,
+// reserved through xtid.Generator.MakeWithTrailer, and counts from the
+// timestamp of the ID. This leaves at most 6 random bytes per timestamp and
+// type, so IDs minted with Make should not be relied upon being unguessable:
+// use a Signer to hand them out as bearer tokens. The generators may reserve
+// leading payload bytes with xtid.WithNodeID or xtid.WithTenant, but can't
+// store a region with xtid.WithRegion.
+package xtidttl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+const (
+	// The offset of the TTL in the binary form of an ID
+	ttlOffset = 16
+	ttlLength = 4
+
+	// The length of the truncated HMAC of signed tokens
+	macLength = 16
+)
+
+// MaxTTL is the longest time to live an ID can carry.
+const MaxTTL = math.MaxUint32 * time.Second
+
+var (
+	ErrTTL       = errors.New("xtidttl: TTL out of range")
+	ErrExpired   = errors.New("xtidttl: ID expired")
+	ErrSignature = errors.New("xtidttl: invalid token signature")
+	ErrToken     = errors.New("xtidttl: malformed token")
+)
+
+// Make mints a XTID of type typ with gen, or the default generator when gen
+// is nil, expiring ttl after now. ttl is rounded up to a whole second.
+func Make(gen *xtid.Generator, typ uint16, ttl time.Duration) (xtid.XTID, error) {
+	if ttl <= 0 || ttl > MaxTTL {
+		return xtid.Nil, ErrTTL
+	}
+	if gen == nil {
+		gen = xtid.DefaultGenerator()
+	}
+	secs := (ttl + time.Second - 1) / time.Second
+	var trailer [ttlLength]byte
+	binary.BigEndian.PutUint32(trailer[:], uint32(secs))
+	return gen.MakeWithTrailer(time.Now(), typ, trailer[:])
+}
+
+// TTL returns the time to live carried by id.
+func TTL(id xtid.XTID) time.Duration {
+	b := id.Bytes()
+	return time.Duration(binary.BigEndian.Uint32(b[ttlOffset:ttlOffset+ttlLength])) * time.Second
+}
+
+// ExpiresAt returns the time at which id expires.
+func ExpiresAt(id xtid.XTID) time.Time {
+	return id.Time().Add(TTL(id))
+}
+
+// Expired reports whether id has expired.
+func Expired(id xtid.XTID) bool {
+	return ExpiredAt(id, time.Now())
+}
+
+// ExpiredAt reports whether id has expired at time t.
+func ExpiredAt(id xtid.XTID, t time.Time) bool {
+	return !t.Before(ExpiresAt(id))
+}
+
+// A Signer hands out expiring IDs as tokens authenticated with HMAC-SHA256,
+// of the form <id>.<signature>, and verifies them.
+type Signer struct {
+	key []byte
+	gen *xtid.Generator
+}
+
+// NewSigner returns a Signer using key, minting IDs with gen, or the default
+// generator when gen is nil.
+func NewSigner(key []byte, gen *xtid.Generator) *Signer {
+	return &Signer{key: append([]byte(nil), key...), gen: gen}
+}
+
+// Make mints an ID of type typ expiring ttl after now, and returns it along
+// with its token.
+func (s *Signer) Make(typ uint16, ttl time.Duration) (xtid.XTID, string, error) {
+	id, err := Make(s.gen, typ, ttl)
+	if err != nil {
+		return xtid.Nil, "", err
+	}
+	return id, s.Sign(id), nil
+}
+
+// Sign returns the token of id.
+func (s *Signer) Sign(id xtid.XTID) string {
+	return id.String() + "." + base64.RawURLEncoding.EncodeToString(s.mac(id))
+}
+
+// Verify checks the signature and expiry of token, and returns its ID.
+func (s *Signer) Verify(token string) (xtid.XTID, error) {
+	return s.VerifyAt(token, time.Now())
+}
+
+// VerifyAt is like Verify, checking the expiry at time t.
+func (s *Signer) VerifyAt(token string, t time.Time) (xtid.XTID, error) {
+	str, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return xtid.Nil, ErrToken
+	}
+	id, err := xtid.Parse(str)
+	if err != nil {
+		return xtid.Nil, ErrToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.mac(id)) {
+		return xtid.Nil, ErrSignature
+	}
+	if ExpiredAt(id, t) {
+		return xtid.Nil, ErrExpired
+	}
+	return id, nil
+}
+
+func (s *Signer) mac(id xtid.XTID) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write(id.Bytes())
+	return h.Sum(nil)[:macLength]
+}
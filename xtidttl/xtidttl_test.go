@@ -0,0 +1,109 @@
+package xtidttl
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/it512/xtid"
+)
+
+func TestMake(t *testing.T) {
+	nodeGen, err := xtid.NewGenerator(xtid.WithNodeID(0xabcd, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	monoGen, err := xtid.NewGenerator(xtid.WithMonotonic())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		gen  *xtid.Generator
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{"default generator", nil, time.Minute, time.Minute},
+		{"rounded up", nil, 1500 * time.Millisecond, 2 * time.Second},
+		{"max", nil, MaxTTL, MaxTTL},
+		{"node ID", nodeGen, time.Hour, time.Hour},
+		{"monotonic", monoGen, time.Second, time.Second},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := Make(tt.gen, 7, tt.ttl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := TTL(id); got != tt.want {
+				t.Errorf("TTL = %v, want %v", got, tt.want)
+			}
+			if id.Type() != 7 {
+				t.Errorf("Type = %d, want 7", id.Type())
+			}
+			if !ExpiresAt(id).Equal(id.Time().Add(tt.want)) {
+				t.Errorf("ExpiresAt = %v, want %v", ExpiresAt(id), id.Time().Add(tt.want))
+			}
+		})
+	}
+
+	// The TTL keeps the node ID in the leading payload bytes
+	id, err := Make(nodeGen, 1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := id.Payload(); !bytes.Equal(p[:2], []byte{0xab, 0xcd}) {
+		t.Errorf("payload %x lost the node ID", p)
+	}
+
+	// Monotonic IDs keep increasing, with the TTL intact
+	prev := xtid.Nil
+	for range 100 {
+		id, err := Make(monoGen, 1, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if xtid.Compare(id, prev) <= 0 || TTL(id) != time.Minute {
+			t.Fatalf("ID %s after %s, TTL %v", id, prev, TTL(id))
+		}
+		prev = id
+	}
+}
+
+func TestMakeErrors(t *testing.T) {
+	for _, ttl := range []time.Duration{0, -time.Second, MaxTTL + time.Second} {
+		if _, err := Make(nil, 1, ttl); !errors.Is(err, ErrTTL) {
+			t.Errorf("Make(%v) = %v, want %v", ttl, err, ErrTTL)
+		}
+	}
+	regionGen, err := xtid.NewGenerator(xtid.WithRegion(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Make(regionGen, 1, time.Minute); err == nil {
+		t.Error("Make with a region succeeded")
+	}
+}
+
+func TestSigner(t *testing.T) {
+	s := NewSigner([]byte("key"), nil)
+	id, token, err := s.Make(1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Verify(token); err != nil || got != id {
+		t.Fatalf("Verify = %s, %v, want %s", got, err, id)
+	}
+	if _, err := s.VerifyAt(token, ExpiresAt(id)); !errors.Is(err, ErrExpired) {
+		t.Errorf("VerifyAt expiry = %v, want %v", err, ErrExpired)
+	}
+	if _, err := NewSigner([]byte("other"), nil).Verify(token); !errors.Is(err, ErrSignature) {
+		t.Errorf("Verify with another key = %v, want %v", err, ErrSignature)
+	}
+	for _, bad := range []string{"", id.String(), "x." + token[28:]} {
+		if _, err := s.Verify(bad); !errors.Is(err, ErrToken) {
+			t.Errorf("Verify(%q) = %v, want %v", bad, err, ErrToken)
+		}
+	}
+}